@@ -0,0 +1,281 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RevokedRefreshTokenCodes 刷新 token 时表示 refresh_token 已失效(被撤销或过期)的错误码
+var RevokedRefreshTokenCodes = []string{
+	"InvalidParameter.RefreshToken",
+	"RefreshTokenExpired",
+	"InvalidRefreshToken",
+}
+
+// Account 账号管理器中的单个账号
+type Account struct {
+	UserID  string // 用户ID
+	DriveID string // 云盘ID
+
+	mu        sync.RWMutex
+	refreshMu sync.Mutex // 串行化同一账号的刷新, refresh_token 只能使用一次
+	authorize Authorize
+	revoked   bool
+	lastError error
+}
+
+// Authorize 返回当前授权信息的副本, 可并发调用
+func (acc *Account) Authorize() *Authorize {
+	acc.mu.RLock()
+	defer acc.mu.RUnlock()
+	authorize := acc.authorize
+	return &authorize
+}
+
+// Revoked refresh_token 是否已失效
+func (acc *Account) Revoked() bool {
+	acc.mu.RLock()
+	defer acc.mu.RUnlock()
+	return acc.revoked
+}
+
+// LastError 最近一次刷新失败的错误
+func (acc *Account) LastError() error {
+	acc.mu.RLock()
+	defer acc.mu.RUnlock()
+	return acc.lastError
+}
+
+// ExpiresTime access_token 过期时间
+func (acc *Account) ExpiresTime() time.Time {
+	acc.mu.RLock()
+	defer acc.mu.RUnlock()
+	return acc.authorize.ExpiresTime
+}
+
+// AccountManager 多账号管理器, 按用户ID/云盘ID索引账号, 并负责定时刷新 token
+type AccountManager struct {
+	RefreshBefore time.Duration      // 过期前多久开始刷新
+	CheckInterval time.Duration      // 检查间隔
+	OnRefresh     func(acc *Account) // 刷新成功回调, 可用于持久化新的 refresh_token
+	OnRevoked     func(acc *Account) // refresh_token 失效回调
+
+	client   *Client
+	mu       sync.RWMutex
+	accounts map[string]*Account // user_id -> Account
+	drives   map[string]string   // drive_id -> user_id
+}
+
+// NewAccountManager 创建多账号管理器
+func NewAccountManager(client *Client) *AccountManager {
+	return &AccountManager{
+		RefreshBefore: time.Minute * 10,
+		CheckInterval: time.Minute,
+		client:        client,
+		accounts:      make(map[string]*Account),
+		drives:        make(map[string]string),
+	}
+}
+
+// Add 添加账号, 通过 DriveInfo 获取用户ID. 已存在的账号会被覆盖
+func (m *AccountManager) Add(authorize Authorize) (acc *Account, err error) {
+	info, err := authorize.DriveInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if authorize.DriveID == "" {
+		authorize.DriveID = info.DefaultDriveId
+	}
+
+	acc = &Account{
+		UserID:    info.UserId,
+		DriveID:   authorize.DriveID,
+		authorize: authorize,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.accounts[acc.UserID]; ok {
+		delete(m.drives, old.DriveID)
+	}
+	m.accounts[acc.UserID] = acc
+	m.drives[acc.DriveID] = acc.UserID
+
+	return acc, nil
+}
+
+// AddRefreshToken 通过 refresh_token 添加账号
+func (m *AccountManager) AddRefreshToken(refreshToken string) (acc *Account, err error) {
+	client := *m.client
+	client.DriveID = ""
+
+	authorize, err := client.RefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Add(authorize)
+}
+
+// Get 根据用户ID或云盘ID获取账号
+func (m *AccountManager) Get(id string) (acc *Account, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if acc, ok = m.accounts[id]; ok {
+		return acc, ok
+	}
+
+	if userID, exist := m.drives[id]; exist {
+		acc, ok = m.accounts[userID]
+	}
+
+	return acc, ok
+}
+
+// Authorize 根据用户ID或云盘ID获取当前授权信息
+func (m *AccountManager) Authorize(id string) (*Authorize, error) {
+	acc, ok := m.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("账号(%s)不存在", id)
+	}
+
+	if acc.Revoked() {
+		return nil, fmt.Errorf("账号(%s)授权已失效: %s", id, acc.LastError())
+	}
+
+	return acc.Authorize(), nil
+}
+
+// Remove 根据用户ID或云盘ID移除账号
+func (m *AccountManager) Remove(id string) {
+	acc, ok := m.Get(id)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.accounts, acc.UserID)
+	delete(m.drives, acc.DriveID)
+}
+
+// Accounts 返回所有账号
+func (m *AccountManager) Accounts() []*Account {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	accounts := make([]*Account, 0, len(m.accounts))
+	for _, acc := range m.accounts {
+		accounts = append(accounts, acc)
+	}
+	return accounts
+}
+
+// Revoked 返回 refresh_token 已失效的账号
+func (m *AccountManager) Revoked() []*Account {
+	accounts := make([]*Account, 0)
+	for _, acc := range m.Accounts() {
+		if acc.Revoked() {
+			accounts = append(accounts, acc)
+		}
+	}
+	return accounts
+}
+
+// Refresh 立即刷新指定账号的 token
+func (m *AccountManager) Refresh(id string) error {
+	acc, ok := m.Get(id)
+	if !ok {
+		return fmt.Errorf("账号(%s)不存在", id)
+	}
+	return m.refresh(acc, time.Time{})
+}
+
+// refresh 刷新账号 token. deadline 不为零值时, 只在过期时间早于 deadline 时刷新
+func (m *AccountManager) refresh(acc *Account, deadline time.Time) error {
+	refreshed, revoked, err := m.doRefresh(acc, deadline)
+
+	// 回调在释放刷新锁后执行, 回调中可以再次调用 Refresh
+	if revoked && m.OnRevoked != nil {
+		m.OnRevoked(acc)
+	}
+	if refreshed && m.OnRefresh != nil {
+		m.OnRefresh(acc)
+	}
+	return err
+}
+
+// doRefresh 持有刷新锁执行刷新, 返回是否刷新成功和 refresh_token 是否已失效
+func (m *AccountManager) doRefresh(acc *Account, deadline time.Time) (refreshed, revoked bool, err error) {
+	acc.refreshMu.Lock()
+	defer acc.refreshMu.Unlock()
+
+	// 获取锁后再读取, 等待期间可能已被其他调用刷新
+	acc.mu.RLock()
+	current := acc.authorize
+	skip := acc.revoked
+	acc.mu.RUnlock()
+
+	if !deadline.IsZero() && (skip || current.ExpiresTime.After(deadline)) {
+		return false, false, nil
+	}
+
+	// Refresh 使用 Client 副本, 避免共享的 DriveID 串号
+	current.DriveID = acc.DriveID
+	result, err := current.Refresh(m.client)
+
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	if err != nil {
+		acc.lastError = err
+		acc.revoked = isRevokedRefreshToken(result.Code)
+		return false, acc.revoked, err
+	}
+
+	acc.authorize = result
+	acc.revoked = false
+	acc.lastError = nil
+	return true, false, nil
+}
+
+// RefreshExpiring 刷新所有即将过期的账号, 已失效的账号会被跳过
+func (m *AccountManager) RefreshExpiring() {
+	deadline := time.Now().Add(m.RefreshBefore)
+	for _, acc := range m.Accounts() {
+		if acc.Revoked() || acc.ExpiresTime().After(deadline) {
+			continue
+		}
+		_ = m.refresh(acc, deadline)
+	}
+}
+
+// Start 定时刷新即将过期的账号, 直到 ctx 结束
+func (m *AccountManager) Start(ctx context.Context) {
+	m.RefreshExpiring()
+
+	ticker := time.NewTicker(m.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.RefreshExpiring()
+		}
+	}
+}
+
+func isRevokedRefreshToken(code string) bool {
+	for _, c := range RevokedRefreshTokenCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
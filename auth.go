@@ -34,6 +34,9 @@ func (c *Client) AuthorizeURL(option *AuthorizeOption) (authURL string, err erro
 	values.Set("client_id", c.ClientId)
 	values.Set("redirect_uri", option.RedirectUri)
 	values.Set("scope", joinCustomString(option.Scopes, ","))
+	if option.State != "" {
+		values.Set("state", option.State)
+	}
 
 	u, _ := url.Parse(APIAuthorizeMultiple)
 	u.RawQuery = values.Encode()
@@ -42,6 +45,7 @@ func (c *Client) AuthorizeURL(option *AuthorizeOption) (authURL string, err erro
 }
 
 // ReceiveAuthorizeCode 接收前端授权 code, 并获得授权
+// 不记录授权范围, 需要写操作提前检查权限时使用 ReceiveAuthorizeCodeWithOption 或 Authorize.SetScopes
 func (c *Client) ReceiveAuthorizeCode(req *http.Request) (result Authorize, err error) {
 	queryParams := req.URL.Query()
 
@@ -53,6 +57,16 @@ func (c *Client) ReceiveAuthorizeCode(req *http.Request) (result Authorize, err
 	return c.Authorize(code)
 }
 
+// ReceiveAuthorizeCodeWithOption 接收前端授权 code 并获得授权, 接口未返回授权范围时记录 option.Scopes
+func (c *Client) ReceiveAuthorizeCodeWithOption(req *http.Request, option *AuthorizeOption) (result Authorize, err error) {
+	code := req.URL.Query().Get("code")
+	if code == "" {
+		err = fmt.Errorf("code 为空")
+		return result, err
+	}
+	return c.AuthorizeWithOption(code, option)
+}
+
 // AuthorizeQRCode 授权二维码数据
 type AuthorizeQRCode struct {
 	QrCodeUrl string `json:"qrCodeUrl"`
@@ -93,7 +107,7 @@ func (c *Client) QrCodeStatus(sid string) (result AuthorizeQRCodeStatus, err err
 		return result, err
 	}
 
-	_, err = RestyHttpClient.R().SetResult(&result).Get(fmt.Sprintf(APIAuthorizeQrCodeStatus, sid))
+	err = httpDo(http.MethodGet, fmt.Sprintf(APIAuthorizeQrCodeStatus, sid), EndpointClassRead, http.Header{}, nil, &result)
 	if err != nil {
 		return result, err
 	}
@@ -107,16 +121,20 @@ func (c *Client) QrCodeStatus(sid string) (result AuthorizeQRCodeStatus, err err
 
 // Authorize 登录授权信息
 type Authorize struct {
-	TokenType    string    `json:"token_type"`
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresIn    int       `json:"expires_in"`
-	ExpiresTime  time.Time `json:"expires_time"`
-	DriveID      string    `json:"drive_id"`
+	TokenType    string      `json:"token_type"`
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    int         `json:"expires_in"`
+	ExpiresTime  time.Time   `json:"expires_time"`
+	DriveID      string      `json:"drive_id"`
+	Scope        string      `json:"scope,omitempty"` // 已授权范围, 逗号分隔
+	MetaCache    *MetaCache  `json:"-"`               // 文件元数据缓存, 为空时不缓存
+	Quota        *QuotaGuard `json:"-"`               // 上传前检查剩余空间, 为空时不检查
 	ErrorInfo
 }
 
 // Authorize 授权登录
+// 不记录授权范围, 需要写操作提前检查权限时使用 AuthorizeWithOption 或 Authorize.SetScopes
 func (c *Client) Authorize(authCode string) (result Authorize, err error) {
 	if authCode == "" {
 		err = fmt.Errorf("需要传入 QrCodeStatus 方法返回 authCode 值")
@@ -152,7 +170,17 @@ func (c *Client) Authorize(authCode string) (result Authorize, err error) {
 	return result, err
 }
 
+// AuthorizeWithOption 授权登录, 接口未返回授权范围时记录申请时的 option.Scopes, 缺少权限的操作会提前返回 *ScopeError
+func (c *Client) AuthorizeWithOption(authCode string, option *AuthorizeOption) (result Authorize, err error) {
+	result, err = c.Authorize(authCode)
+	if result.Scope == "" && option != nil {
+		result.SetScopes(option.Scopes)
+	}
+	return result, err
+}
+
 // RefreshToken 刷新 token
+// 返回的授权信息可能不包含授权范围, 刷新已有授权时使用 Authorize.Refresh 保留授权范围
 func (c *Client) RefreshToken(refreshToken string) (result Authorize, err error) {
 	req := map[string]string{
 		"client_id":     c.ClientId,
@@ -182,3 +210,23 @@ func (c *Client) RefreshToken(refreshToken string) (result Authorize, err error)
 	result.DriveID = c.DriveID
 	return result, err
 }
+
+// Refresh 使用当前 refresh_token 刷新授权, 返回新的授权信息
+// 保留当前的 DriveID, Scope(接口未返回时), MetaCache 和 Quota, 不修改 c
+func (a *Authorize) Refresh(c *Client) (result Authorize, err error) {
+	client := *c
+	client.DriveID = a.DriveID
+
+	result, err = client.RefreshToken(a.RefreshToken)
+	if err != nil {
+		return result, err
+	}
+
+	result.DriveID = a.DriveID
+	if result.Scope == "" {
+		result.Scope = a.Scope
+	}
+	result.MetaCache = a.MetaCache
+	result.Quota = a.Quota
+	return result, nil
+}
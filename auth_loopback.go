@@ -0,0 +1,143 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultLoopbackTimeout 本地回调授权默认等待时间(ctx 未设置超时时使用)
+var DefaultLoopbackTimeout = time.Minute * 5
+
+// DefaultLoopbackPath 本地回调授权默认回调路径
+const DefaultLoopbackPath = "/callback"
+
+// NewLoopbackAuthorizeOption 创建桌面/命令行应用本地回调授权选项
+// port 需要与开放平台后台配置的回调地址一致, 例如后台配置 http://127.0.0.1:8899/callback 则传入 8899
+// State 留空, 由 LoopbackAuthorize 使用 crypto/rand 生成
+func NewLoopbackAuthorizeOption(port int) *AuthorizeOption {
+	option := NewDefaultMultipleAuthorizeOption(fmt.Sprintf("http://127.0.0.1:%d%s", port, DefaultLoopbackPath))
+	option.State = ""
+	return option
+}
+
+type loopbackCallback struct {
+	code string
+	err  error
+}
+
+// LoopbackAuthorize 桌面/命令行应用本地回调授权
+// 在 127.0.0.1 启动临时 HTTP 服务接收回调, 构建授权地址后交给 open 打开(open 为空时直接打印),
+// 收到回调后校验 state, 通过 Authorize 换取 token, 结束后关闭监听
+func (c *Client) LoopbackAuthorize(ctx context.Context, option *AuthorizeOption, open func(authURL string) error) (result Authorize, err error) {
+	if option == nil {
+		return result, fmt.Errorf("option is nil")
+	}
+
+	if option.RedirectUri == "" {
+		return result, fmt.Errorf("RedirectUri 为空, 请使用 NewLoopbackAuthorizeOption 创建选项")
+	}
+
+	redirect, err := url.Parse(option.RedirectUri)
+	if err != nil {
+		return result, fmt.Errorf("解析回调地址失败: %s", err)
+	}
+
+	if redirect.Hostname() != "127.0.0.1" || redirect.Port() == "" {
+		return result, fmt.Errorf("回调地址必须为 127.0.0.1 并指定端口: %s", option.RedirectUri)
+	}
+
+	if option.State == "" {
+		option.State, err = secureRandomString(16)
+		if err != nil {
+			return result, fmt.Errorf("生成 state 失败: %s", err)
+		}
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultLoopbackTimeout)
+		defer cancel()
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return result, fmt.Errorf("启动本地回调服务失败: %s", err)
+	}
+
+	callback := make(chan loopbackCallback, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var cb loopbackCallback
+		switch {
+		case query.Get("error") != "":
+			cb.err = fmt.Errorf("授权失败: %s", query.Get("error"))
+		case query.Get("state") != option.State:
+			cb.err = fmt.Errorf("state 校验失败")
+		case query.Get("code") == "":
+			cb.err = fmt.Errorf("code 为空")
+		default:
+			cb.code = query.Get("code")
+		}
+
+		if cb.err != nil {
+			http.Error(w, cb.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprint(w, "授权成功, 可以关闭此页面")
+		}
+
+		select {
+		case callback <- cb:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case callback <- loopbackCallback{err: fmt.Errorf("本地回调服务异常: %s", err)}:
+			default:
+			}
+		}
+	}()
+
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	authURL, err := c.AuthorizeURL(option)
+	if err != nil {
+		return result, err
+	}
+
+	if open == nil {
+		fmt.Println("打开以下地址完成授权")
+		fmt.Println(authURL)
+	} else if err = open(authURL); err != nil {
+		return result, fmt.Errorf("打开授权地址失败: %s", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return result, fmt.Errorf("等待授权回调超时: %s", ctx.Err())
+	case cb := <-callback:
+		if cb.err != nil {
+			return result, cb.err
+		}
+		return c.AuthorizeWithOption(cb.code, option)
+	}
+}
@@ -0,0 +1,304 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BatchMaxSize 单次批量请求最多包含的操作数
+var BatchMaxSize = 100
+
+// BatchOperation 批量操作类型
+type BatchOperation string
+
+const (
+	BatchOperationMove   BatchOperation = "move"   // 移动
+	BatchOperationCopy   BatchOperation = "copy"   // 复制
+	BatchOperationTrash  BatchOperation = "trash"  // 放入回收站
+	BatchOperationDelete BatchOperation = "delete" // 彻底删除
+	BatchOperationUpdate BatchOperation = "update" // 更新(重命名)
+)
+
+// BatchOperationURLs 支持批量接口的操作及其对应的子请求地址, 未登记的操作返回错误
+var BatchOperationURLs = map[BatchOperation]string{
+	BatchOperationMove:   "/openFile/move",
+	BatchOperationCopy:   "/openFile/copy",
+	BatchOperationTrash:  "/openFile/recyclebin/trash",
+	BatchOperationDelete: "/openFile/delete",
+	BatchOperationUpdate: "/openFile/update",
+}
+
+// BatchUnsupportedCodes 批量接口返回这些错误码时视为不支持该子请求, 改为调用单独接口重试
+var BatchUnsupportedCodes = []string{
+	"NotSupported",
+	"UnsupportedOperation",
+	"InvalidParameter.Url",
+}
+
+// BatchRequest 批量操作中的单个请求
+type BatchRequest struct {
+	ID        string         // 请求ID(不可重复), 为空时使用序号(与其他请求ID冲突时加后缀)
+	Operation BatchOperation // 操作类型
+	Option    *FileOption    // 操作参数, 与单独调用时相同
+}
+
+// BatchResult 批量操作中单个请求的结果
+type BatchResult struct {
+	ID        string          `json:"id"`
+	Operation BatchOperation  `json:"-"`
+	Status    int             `json:"status"`
+	Body      json.RawMessage `json:"body"`
+	Err       error           `json:"-"`
+}
+
+// Decode 解析单个请求的返回数据
+func (r *BatchResult) Decode(v interface{}) error {
+	if len(r.Body) == 0 {
+		return fmt.Errorf("返回数据为空")
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+// NewBatchRequests 为多个文件创建同一操作的批量请求, newOption 根据文件ID生成操作参数
+func NewBatchRequests(operation BatchOperation, fileIDs []string, newOption func(fileID string) *FileOption) []BatchRequest {
+	requests := make([]BatchRequest, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		requests = append(requests, BatchRequest{
+			ID:        id,
+			Operation: operation,
+			Option:    newOption(id),
+		})
+	}
+	return requests
+}
+
+type batchSubRequest struct {
+	ID      string            `json:"id"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Body    *FileOption       `json:"body"`
+	Headers map[string]string `json:"headers"`
+}
+
+type batchResponse struct {
+	Responses []BatchResult `json:"responses"`
+	ErrorInfo
+}
+
+// Batch 批量执行文件操作. 按 BatchMaxSize 分组调用批量接口, 批量接口不支持的子请求改为调用单独接口
+// 请求ID重复时不执行任何操作直接返回错误. 返回结果与 requests 顺序一致, 每个结果的 Err 为该操作的错误; 存在失败操作时同时返回汇总错误
+// 移动/复制由接口按 check_name_mode 处理重名, 不支持 ConflictPolicy(只接受默认的 ConflictPolicyAutoRename, 且忽略 RenameTemplate),
+// 需要其他重名处理策略时请单独调用 FileMove/FileCopy
+func (a *Authorize) Batch(requests []BatchRequest) (results []BatchResult, err error) {
+	if len(requests) == 0 {
+		return results, fmt.Errorf("requests is nil")
+	}
+
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return results, err
+	}
+
+	ids, err := batchRequestIDs(requests)
+	if err != nil {
+		return results, err
+	}
+
+	results = make([]BatchResult, len(requests))
+	batchIndexes := make([]int, 0, len(requests))
+	for i, req := range requests {
+		results[i].ID = ids[i]
+		results[i].Operation = req.Operation
+
+		if req.Option == nil {
+			results[i].Err = fmt.Errorf("option is nil")
+			continue
+		}
+
+		if _, ok := BatchOperationURLs[req.Operation]; !ok {
+			results[i].Err = fmt.Errorf("不支持的批量操作: %s", req.Operation)
+			continue
+		}
+
+		if req.Operation == BatchOperationMove || req.Operation == BatchOperationCopy {
+			if p := req.Option.ConflictPolicy; p != "" && p != ConflictPolicyAutoRename {
+				results[i].Err = fmt.Errorf("批量操作不支持重名处理策略: %s", p)
+				continue
+			}
+		}
+
+		batchIndexes = append(batchIndexes, i)
+	}
+
+	for start := 0; start < len(batchIndexes); start += BatchMaxSize {
+		end := start + BatchMaxSize
+		if end > len(batchIndexes) {
+			end = len(batchIndexes)
+		}
+		a.batchChunk(requests, ids, batchIndexes[start:end], results)
+	}
+
+	errIDs := make([]string, 0)
+	for _, r := range results {
+		if r.Err != nil {
+			errIDs = append(errIDs, strings.Join([]string{r.ID, r.Err.Error()}, ":"))
+		}
+	}
+
+	if len(errIDs) > 0 {
+		err = fmt.Errorf("失败信息: %s", strings.Join(errIDs, ","))
+	}
+
+	return results, err
+}
+
+// batchRequestIDs 生成各请求使用的ID, 不修改 requests. 为空的ID使用序号, 与已有ID冲突时加后缀
+func batchRequestIDs(requests []BatchRequest) ([]string, error) {
+	ids := make([]string, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i, req := range requests {
+		if req.ID == "" {
+			continue
+		}
+		if seen[req.ID] {
+			return nil, fmt.Errorf("批量操作请求ID重复: %s", req.ID)
+		}
+		seen[req.ID] = true
+		ids[i] = req.ID
+	}
+
+	for i := range requests {
+		if ids[i] != "" {
+			continue
+		}
+
+		id := strconv.Itoa(i)
+		for n := 1; seen[id]; n++ {
+			id = strconv.Itoa(i) + "-" + strconv.Itoa(n)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// batchChunk 执行一组批量请求, 结果写入 results 对应位置
+func (a *Authorize) batchChunk(requests []BatchRequest, ids []string, indexes []int, results []BatchResult) {
+	subRequests := make([]batchSubRequest, 0, len(indexes))
+	positions := make(map[string]int, len(indexes))
+	for _, i := range indexes {
+		req := requests[i]
+		req.Option.SetDriveID(a.DriveID)
+		subRequests = append(subRequests, batchSubRequest{
+			ID:      ids[i],
+			Method:  "POST",
+			URL:     BatchOperationURLs[req.Operation],
+			Body:    req.Option,
+			Headers: map[string]string{"Content-Type": "application/json"},
+		})
+		positions[ids[i]] = i
+	}
+
+	fileIDs := make([]string, 0, len(indexes))
+	parentIDs := make([]string, 0)
+	for _, i := range indexes {
+		fileIDs = append(fileIDs, requests[i].Option.FileID)
+		if requests[i].Option.ToParentFileID != "" {
+			parentIDs = append(parentIDs, requests[i].Option.ToParentFileID)
+		}
+	}
+	defer a.invalidateTree(fileIDs, parentIDs...)
+
+	var resp batchResponse
+	err := a.HttpPost(APIBatch, map[string]interface{}{
+		"resource": "file",
+		"requests": subRequests,
+	}, &resp)
+	if err == nil && resp.Code != "" {
+		err = fmt.Errorf("批量操作失败: %s", resp.Message)
+	}
+
+	if err != nil {
+		for _, i := range indexes {
+			results[i].Err = err
+		}
+		return
+	}
+
+	for _, r := range resp.Responses {
+		i, ok := positions[r.ID]
+		if !ok {
+			continue
+		}
+		delete(positions, r.ID)
+
+		r.Operation = results[i].Operation
+		if r.Status >= 300 {
+			var info ErrorInfo
+			_ = json.Unmarshal(r.Body, &info)
+
+			if batchUnsupported(r.Status, info.Code) {
+				results[i] = a.batchSingle(ids[i], requests[i])
+				continue
+			}
+			r.Err = fmt.Errorf("批量操作失败(%d): %s", r.Status, info.Message)
+		}
+		results[i] = r
+	}
+
+	for id, i := range positions {
+		results[i].Err = fmt.Errorf("批量操作未返回结果: %s", id)
+	}
+}
+
+// batchUnsupported 子请求是否因批量接口不支持而失败
+func batchUnsupported(status int, code string) bool {
+	if status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		return true
+	}
+
+	if status < 400 || status >= 500 {
+		return false
+	}
+
+	for _, c := range BatchUnsupportedCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// batchSingle 批量接口不支持的子请求调用单独接口
+func (a *Authorize) batchSingle(id string, req BatchRequest) (result BatchResult) {
+	result.ID = id
+	result.Operation = req.Operation
+
+	var data interface{}
+	var err error
+	switch req.Operation {
+	case BatchOperationMove:
+		data, err = a.FileMove(req.Option)
+	case BatchOperationCopy:
+		data, err = a.FileCopy(req.Option)
+	case BatchOperationTrash:
+		data, err = a.FileTrash(req.Option)
+	case BatchOperationDelete:
+		data, err = a.FileDelete(req.Option)
+	case BatchOperationUpdate:
+		data, err = a.FileRename(req.Option)
+	default:
+		err = fmt.Errorf("不支持的批量操作: %s", req.Operation)
+	}
+
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Status = 200
+	result.Body, result.Err = json.Marshal(data)
+	return result
+}
@@ -0,0 +1,329 @@
+package aliyundrive_open
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetaCacheStore 元数据缓存的持久化存储. key 为以 / 分隔的路径
+type MetaCacheStore interface {
+	Get(key string) (data []byte, expire time.Time, ok bool)
+	Set(key string, data []byte, expire time.Time) error
+	Delete(prefix string) error // 删除 prefix 本身及 prefix+"/" 开头的所有项
+}
+
+// MetaCache 文件元数据缓存(内存 LRU + 可选持久化存储)
+// 设置到 Authorize.MetaCache 后, File/Files/FileList 优先读取缓存, 修改类操作会自动失效相关缓存
+type MetaCache struct {
+	TTL time.Duration // 缓存有效期
+
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	store    MetaCacheStore
+	gen      uint64 // 每次 Invalidate 递增, 用于丢弃失效期间读写存储的过期数据
+
+	storeMu sync.Mutex // 串行化存储写入和删除, 存储读写不持有 mu, 避免磁盘 I/O 阻塞内存读取
+}
+
+type metaCacheItem struct {
+	key    string
+	data   []byte
+	expire time.Time
+}
+
+// NewMetaCache 创建元数据缓存, capacity 为内存中最多缓存的条目数
+func NewMetaCache(capacity int, ttl time.Duration) *MetaCache {
+	return &MetaCache{
+		TTL:      ttl,
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// SetStore 设置持久化存储, 内存未命中时从存储读取
+func (c *MetaCache) SetStore(store MetaCacheStore) *MetaCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+	return c
+}
+
+// Get 读取缓存并解析到 v
+func (c *MetaCache) Get(key string, v interface{}) bool {
+	data, ok := c.getBytes(key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (c *MetaCache) getBytes(key string) ([]byte, bool) {
+	c.mu.Lock()
+	now := time.Now()
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*metaCacheItem)
+		if now.Before(item.expire) {
+			c.ll.MoveToFront(el)
+			c.mu.Unlock()
+			return item.data, true
+		}
+		c.removeElement(el)
+	}
+	store, gen := c.store, c.gen
+	c.mu.Unlock()
+
+	if store == nil {
+		return nil, false
+	}
+
+	data, expire, ok := store.Get(key)
+	if !ok || !now.Before(expire) {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	if _, exist := c.items[key]; !exist && c.gen == gen {
+		c.add(key, data, expire)
+	}
+	c.mu.Unlock()
+	return data, true
+}
+
+// Set 写入缓存
+func (c *MetaCache) Set(key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	expire := time.Now().Add(c.TTL)
+
+	c.mu.Lock()
+	c.add(key, data, expire)
+	store, gen := c.store, c.gen
+	c.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	if c.generation() == gen {
+		_ = store.Set(key, data, expire)
+	}
+}
+
+func (c *MetaCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+func (c *MetaCache) add(key string, data []byte, expire time.Time) {
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*metaCacheItem)
+		item.data = data
+		item.expire = expire
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&metaCacheItem{key: key, data: data, expire: expire})
+	for c.capacity > 0 && c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *MetaCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*metaCacheItem).key)
+}
+
+// Invalidate 删除 prefix 本身及 prefix+"/" 开头的所有缓存
+func (c *MetaCache) Invalidate(prefix string) {
+	c.mu.Lock()
+	for key, el := range c.items {
+		if prefix == "" || key == prefix || strings.HasPrefix(key, prefix+"/") {
+			c.removeElement(el)
+		}
+	}
+	c.gen++
+	store := c.store
+	c.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+	_ = store.Delete(prefix)
+}
+
+// Purge 清空所有缓存
+func (c *MetaCache) Purge() {
+	c.Invalidate("")
+}
+
+// FileMetaCacheStoreSubDir FileMetaCacheStore 在 Dir 下使用的子目录, 清空缓存时只删除该子目录
+const FileMetaCacheStoreSubDir = "aliyundrive_metacache"
+
+// FileMetaCacheStore 基于本地目录的持久化存储, 每个 key 对应 Dir/FileMetaCacheStoreSubDir 下的一个文件
+type FileMetaCacheStore struct {
+	Dir string
+}
+
+type fileMetaCacheRecord struct {
+	Expire time.Time       `json:"expire"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// NewFileMetaCacheStore 创建基于本地目录的持久化存储
+func NewFileMetaCacheStore(dir string) *FileMetaCacheStore {
+	return &FileMetaCacheStore{Dir: dir}
+}
+
+func (s *FileMetaCacheStore) root() string {
+	return filepath.Join(s.Dir, FileMetaCacheStoreSubDir)
+}
+
+func (s *FileMetaCacheStore) path(key string) string {
+	return filepath.Join(s.root(), filepath.FromSlash(key)+".json")
+}
+
+func (s *FileMetaCacheStore) Get(key string) (data []byte, expire time.Time, ok bool) {
+	raw, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, expire, false
+	}
+
+	var record fileMetaCacheRecord
+	if err = json.Unmarshal(raw, &record); err != nil {
+		return nil, expire, false
+	}
+	return record.Data, record.Expire, true
+}
+
+func (s *FileMetaCacheStore) Set(key string, data []byte, expire time.Time) error {
+	raw, err := json.Marshal(fileMetaCacheRecord{Expire: expire, Data: data})
+	if err != nil {
+		return err
+	}
+
+	p := s.path(key)
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名, 避免并发读取到写了一半的记录
+	f, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(raw); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *FileMetaCacheStore) Delete(prefix string) error {
+	if prefix == "" {
+		return os.RemoveAll(s.root())
+	}
+
+	if err := os.Remove(s.path(prefix)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.root(), filepath.FromSlash(prefix)))
+}
+
+// 缓存 key
+func metaCacheFileKey(driveID, fileID string) string {
+	return "file/" + driveID + "/" + fileID
+}
+
+func metaCachePathKey(driveID, path string) string {
+	return "path/" + driveID + "/" + metaCacheHash(path)
+}
+
+func metaCacheListKey(driveID, parentFileID string, option *FileOption) string {
+	data, _ := json.Marshal(option)
+	return "list/" + driveID + "/" + parentFileID + "/" + metaCacheHash(string(data))
+}
+
+func metaCacheHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// invalidateTree 移动/放入回收站/删除/恢复文件后失效相关缓存.
+// 目录的所有子项都会受影响, 无法确认都不是目录时失效该云盘所有文件和目录列表缓存
+func (a *Authorize) invalidateTree(fileIDs []string, parentFileIDs ...string) {
+	c := a.MetaCache
+	if c == nil {
+		return
+	}
+
+	for _, id := range fileIDs {
+		var cached FileInfo
+		if id != "" && (!c.Get(metaCacheFileKey(a.DriveID, id), &cached) || cached.IsDir()) {
+			c.Invalidate("file/" + a.DriveID)
+			c.Invalidate("list/" + a.DriveID)
+			break
+		}
+	}
+
+	a.invalidateFiles(fileIDs, parentFileIDs...)
+}
+
+// invalidateFiles 文件被修改后失效相关缓存. parentFileIDs 为受影响的目录, 为空时失效该云盘所有目录列表
+func (a *Authorize) invalidateFiles(fileIDs []string, parentFileIDs ...string) {
+	c := a.MetaCache
+	if c == nil {
+		return
+	}
+
+	for _, id := range fileIDs {
+		if id == "" {
+			continue
+		}
+
+		var cached FileInfo
+		if c.Get(metaCacheFileKey(a.DriveID, id), &cached) && cached.ParentFileId != "" {
+			parentFileIDs = append(parentFileIDs, cached.ParentFileId)
+		} else {
+			parentFileIDs = append(parentFileIDs, "")
+		}
+
+		c.Invalidate(metaCacheFileKey(a.DriveID, id))
+		c.Invalidate("list/" + a.DriveID + "/" + id)
+	}
+
+	for _, parentID := range parentFileIDs {
+		if parentID == "" {
+			c.Invalidate("list/" + a.DriveID)
+			break
+		}
+		c.Invalidate("list/" + a.DriveID + "/" + parentID)
+	}
+
+	// 路径缓存无法按目录定位, 全部失效
+	c.Invalidate("path/" + a.DriveID)
+}
@@ -0,0 +1,89 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ConflictPolicy 移动/复制时目标目录存在同名文件的处理策略
+type ConflictPolicy string
+
+const (
+	ConflictPolicyAutoRename ConflictPolicy = "auto_rename" // 按模板自动重命名(默认)
+	ConflictPolicyFail       ConflictPolicy = "fail"        // 返回 *FileConflictError
+	ConflictPolicyOverwrite  ConflictPolicy = "overwrite"   // 将已存在的文件放入回收站后覆盖
+	ConflictPolicySkip       ConflictPolicy = "skip"        // 跳过, 不做任何操作
+)
+
+// DefaultRenameTemplate 默认自动重命名模板
+// {base} 不含扩展名的文件名, {ext} 扩展名(含点), {n} 序号, {id} 文件ID后8位
+var DefaultRenameTemplate = "{base}({n}){ext}"
+
+// maxRenameAttempts 自动重命名最多尝试的序号
+const maxRenameAttempts = 1000
+
+// FileConflictError 目标目录存在同名文件
+type FileConflictError struct {
+	ParentFileID string // 目标目录ID
+	Name         string // 重名的文件名
+	ExistFileID  string // 已存在的文件ID
+}
+
+func (e *FileConflictError) Error() string {
+	return fmt.Sprintf("目录(%s)中已存在同名文件: %s", e.ParentFileID, e.Name)
+}
+
+// childrenByName 获取目录下所有文件, 以文件名为键
+func (a *Authorize) childrenByName(parentFileID string) (children map[string]FileInfo, err error) {
+	children = make(map[string]FileInfo)
+
+	option := NewFileListOption(parentFileID, "")
+	for {
+		list, err := a.fileList(option, true)
+		if err != nil {
+			return children, err
+		}
+
+		for _, f := range list.Items {
+			children[f.Name] = f
+		}
+
+		if list.NextMarker == "" {
+			return children, nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
+
+// renderRenameTemplate 按模板生成新文件名
+func renderRenameTemplate(template, name, fileID string, n int) string {
+	if template == "" {
+		template = DefaultRenameTemplate
+	}
+
+	ext := path.Ext(name)
+	shortID := fileID
+	if len(shortID) > 8 {
+		shortID = shortID[len(shortID)-8:]
+	}
+
+	return strings.NewReplacer(
+		"{base}", strings.TrimSuffix(name, ext),
+		"{ext}", ext,
+		"{n}", strconv.Itoa(n),
+		"{id}", shortID,
+	).Replace(template)
+}
+
+// conflictFreeName 生成目录中不存在的文件名
+func conflictFreeName(children map[string]FileInfo, name, fileID, template string) (string, error) {
+	for n := 1; n <= maxRenameAttempts; n++ {
+		candidate := renderRenameTemplate(template, name, fileID, n)
+		if _, exist := children[candidate]; !exist {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("无法为 %s 生成不重名的文件名", name)
+}
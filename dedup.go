@@ -0,0 +1,202 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DedupKeep 重复文件清理时保留哪一个
+type DedupKeep string
+
+const (
+	DedupKeepOldest       DedupKeep = "oldest"        // 保留创建时间最早的
+	DedupKeepNewest       DedupKeep = "newest"        // 保留创建时间最晚的
+	DedupKeepShortestPath DedupKeep = "shortest_path" // 保留路径最短的
+)
+
+// DedupFile 重复文件
+type DedupFile struct {
+	FileID    string    `json:"file_id"`
+	Path      string    `json:"path"` // 相对扫描目录的路径
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DuplicateSet 一组内容相同的文件
+type DuplicateSet struct {
+	ContentHash string      `json:"content_hash"`
+	Size        int64       `json:"size"`
+	Files       []DedupFile `json:"files"`
+	WastedSize  int64       `json:"wasted_size"` // 只保留一份时可释放的空间
+}
+
+// DedupReport 重复文件扫描结果
+type DedupReport struct {
+	FolderID     string         `json:"folder_id"`
+	ScannedFiles int            `json:"scanned_files"`
+	Sets         []DuplicateSet `json:"sets"` // 按可释放空间从大到小排序
+	WastedSize   int64          `json:"wasted_size"`
+}
+
+// JSON 以 JSON 格式输出
+func (r *DedupReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// DedupScan 扫描目录(包括子目录)下的重复文件, 以 ContentHash 和 Size 判断内容相同, 跳过空文件
+func (a *Authorize) DedupScan(folderID string, filter *SyncFilter) (result DedupReport, err error) {
+	result.FolderID = folderID
+
+	entries, err := a.remoteTree(folderID, filter)
+	if err != nil {
+		return result, err
+	}
+
+	groups := make(map[string]*DuplicateSet)
+	for _, rel := range sortedKeys(entries) {
+		e := entries[rel]
+		if e.IsDir {
+			continue
+		}
+
+		result.ScannedFiles++
+		// 空文件内容都相同, 但通常是有意存在的占位/标记文件(如 .gitkeep, __init__.py), 不视为重复
+		if e.Size == 0 || e.File.ContentHash == "" {
+			continue
+		}
+
+		key := strings.ToUpper(e.File.ContentHash) + "/" + strconv.FormatInt(e.Size, 10)
+		set, ok := groups[key]
+		if !ok {
+			set = &DuplicateSet{ContentHash: strings.ToUpper(e.File.ContentHash), Size: e.Size}
+			groups[key] = set
+		}
+
+		set.Files = append(set.Files, DedupFile{
+			FileID:    e.File.FileId,
+			Path:      rel,
+			Size:      e.Size,
+			CreatedAt: e.File.CreatedAt,
+			UpdatedAt: e.File.UpdatedAt,
+		})
+	}
+
+	for _, set := range groups {
+		if len(set.Files) < 2 {
+			continue
+		}
+
+		set.WastedSize = set.Size * int64(len(set.Files)-1)
+		result.WastedSize += set.WastedSize
+		result.Sets = append(result.Sets, *set)
+	}
+
+	sort.Slice(result.Sets, func(i, j int) bool {
+		if result.Sets[i].WastedSize != result.Sets[j].WastedSize {
+			return result.Sets[i].WastedSize > result.Sets[j].WastedSize
+		}
+		return result.Sets[i].Files[0].Path < result.Sets[j].Files[0].Path
+	})
+
+	return result, nil
+}
+
+// DedupClean 重复文件清理结果
+type DedupClean struct {
+	DryRun    bool        `json:"dry_run"`    // 是否仅预览
+	Keep      DedupKeep   `json:"keep"`       // 保留策略
+	Kept      []DedupFile `json:"kept"`       // 保留的文件
+	Trashed   []DedupFile `json:"trashed"`    // 已放入(预览时为将要放入)回收站的文件
+	FreedSize int64       `json:"freed_size"` // 释放空间大小
+	FailedID  []string    `json:"failed_id"`  // 放入回收站失败的文件ID
+}
+
+// JSON 以 JSON 格式输出
+func (r *DedupClean) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// DedupClean 按保留策略清理重复文件, 每组保留一个, 其余放入回收站. dryRun 为 true 时只返回清理计划
+func (a *Authorize) DedupClean(report DedupReport, keep DedupKeep, dryRun bool) (result DedupClean, err error) {
+	result.DryRun = dryRun
+	result.Keep = keep
+
+	less, err := dedupKeepLess(keep)
+	if err != nil {
+		return result, err
+	}
+
+	if !dryRun {
+		if err = a.requireScopes(ScopeWrite); err != nil {
+			return result, err
+		}
+	}
+
+	errFileIDs := make([]string, 0)
+	for _, set := range report.Sets {
+		if len(set.Files) < 2 {
+			continue
+		}
+
+		files := append([]DedupFile(nil), set.Files...)
+		sort.SliceStable(files, func(i, j int) bool {
+			return less(files[i], files[j])
+		})
+		result.Kept = append(result.Kept, files[0])
+
+		for _, f := range files[1:] {
+			if !dryRun {
+				_, err := a.FileTrash(NewFileTrashAndDeleteOption(f.FileID))
+				if err != nil {
+					result.FailedID = append(result.FailedID, f.FileID)
+					errFileIDs = append(errFileIDs, strings.Join([]string{f.FileID, err.Error()}, ":"))
+					continue
+				}
+			}
+
+			result.Trashed = append(result.Trashed, f)
+			result.FreedSize += f.Size
+		}
+	}
+
+	if len(errFileIDs) > 0 {
+		err = fmt.Errorf("失败信息: %s", strings.Join(errFileIDs, ","))
+	}
+
+	return result, err
+}
+
+// dedupKeepLess 返回排序函数, 排在最前的文件被保留
+func dedupKeepLess(keep DedupKeep) (func(a, b DedupFile) bool, error) {
+	switch keep {
+	case DedupKeepOldest, "":
+		return func(a, b DedupFile) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.Path < b.Path
+		}, nil
+	case DedupKeepNewest:
+		return func(a, b DedupFile) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.Path < b.Path
+		}, nil
+	case DedupKeepShortestPath:
+		return func(a, b DedupFile) bool {
+			la, lb := utf8.RuneCountInString(a.Path), utf8.RuneCountInString(b.Path)
+			if la != lb {
+				return la < lb
+			}
+			return a.Path < b.Path
+		}, nil
+	}
+	return nil, fmt.Errorf("不支持的保留策略: %s", keep)
+}
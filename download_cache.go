@@ -0,0 +1,97 @@
+package aliyundrive_open
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDownloadURLMargin 下载链接过期前多久不再复用
+var DefaultDownloadURLMargin = time.Minute * 5
+
+// DownloadURLCache 下载链接缓存, 在过期前复用同一个签名链接. 并发获取同一文件时只请求一次
+type DownloadURLCache struct {
+	Margin time.Duration // 过期前多久视为失效
+
+	mu    sync.Mutex
+	items map[string]FileDownloadURL
+	calls map[string]*downloadURLCall
+}
+
+type downloadURLCall struct {
+	wg     sync.WaitGroup
+	result FileDownloadURL
+	err    error
+}
+
+// NewDownloadURLCache 创建下载链接缓存
+func NewDownloadURLCache(margin time.Duration) *DownloadURLCache {
+	if margin <= 0 {
+		margin = DefaultDownloadURLMargin
+	}
+
+	return &DownloadURLCache{
+		Margin: margin,
+		items:  make(map[string]FileDownloadURL),
+		calls:  make(map[string]*downloadURLCall),
+	}
+}
+
+func downloadURLCacheKey(driveID, fileID string) string {
+	return driveID + "/" + fileID
+}
+
+// Get 获取文件下载链接, 缓存中的链接距离过期超过 Margin 时直接返回
+func (c *DownloadURLCache) Get(a *Authorize, fileID string) (result FileDownloadURL, err error) {
+	key := downloadURLCacheKey(a.DriveID, fileID)
+
+	c.mu.Lock()
+	if item, ok := c.items[key]; ok && time.Now().Add(c.Margin).Before(item.ExpireTime) {
+		c.mu.Unlock()
+		return item, nil
+	}
+
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.result, call.err
+	}
+
+	call := new(downloadURLCall)
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	call.result, call.err = a.FileDownloadURL(NewFileDownloadURLOption(fileID))
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if call.err == nil {
+		c.sweep()
+		c.items[key] = call.result
+	}
+	c.mu.Unlock()
+	call.wg.Done()
+
+	return call.result, call.err
+}
+
+// Invalidate 删除文件的缓存链接, 例如链接提前失效(403)时
+func (c *DownloadURLCache) Invalidate(driveID, fileID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, downloadURLCacheKey(driveID, fileID))
+}
+
+// sweep 缓存较多时清理已失效的链接
+func (c *DownloadURLCache) sweep() {
+	if len(c.items) < 1024 {
+		return
+	}
+
+	now := time.Now().Add(c.Margin)
+	for key, item := range c.items {
+		if !now.Before(item.ExpireTime) {
+			delete(c.items, key)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/yanjunhui/aliyundrive_open"
 	"log"
@@ -35,7 +36,7 @@ func MultipleAuthorize() {
 	/*
 		// 构建一个 authCode 接收服务接口(仅做示例, 实际使用时是要部署在回调地址, 对应服务器上的)
 		http.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
-			authorize, err := client.ReceiveAuthorizeCode(r)
+			authorize, err := client.ReceiveAuthorizeCodeWithOption(r, option)
 			if err != nil {
 				fmt.Fprintf(w, "授权失败: %s", err)
 				return
@@ -52,6 +53,22 @@ func MultipleAuthorize() {
 	*/
 }
 
+// LoopbackLogin 桌面/命令行应用本地回调登录示例
+// 开放平台后台需要配置回调地址 http://127.0.0.1:8899/callback
+func LoopbackLogin() (result aliyundrive_open.Authorize, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	option := aliyundrive_open.NewLoopbackAuthorizeOption(8899)
+
+	// open 传 nil 时直接打印授权地址, 也可以自行调用系统浏览器打开
+	result, err = client.LoopbackAuthorize(ctx, option, nil)
+	if err != nil {
+		log.Printf("登录授权失败: %s\n", err)
+	}
+	return result, err
+}
+
 // GetQRCode 获取登录二维码. 直接打开返回的 qrCodeUrl 就可以看到二维码.
 // sid 参数用于后续 QrCodeStatus 方法获取扫码状态
 func GetQRCode() (result aliyundrive_open.AuthorizeQRCode, err error) {
@@ -137,8 +154,8 @@ func LoginQRCode() (result aliyundrive_open.Authorize, err error) {
 	time.Sleep(3 * time.Second)
 
 	//5. 刷新 access_token
-	//通过 refresh_token 刷新 access_token
-	authorize, err = RefreshToken(authorize.RefreshToken)
+	//通过 refresh_token 刷新 access_token, 保留已授权范围等信息
+	authorize, err = authorize.Refresh(client)
 	if err != nil {
 		log.Printf("刷新Token失败: %s\n", err)
 		return result, err
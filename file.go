@@ -17,60 +17,48 @@ type FileList struct {
 }
 
 type FileInfo struct {
-	Trashed            bool      `json:"trashed"`
-	DriveId            string    `json:"drive_id"`
-	FileId             string    `json:"file_id"`
-	Category           string    `json:"category,omitempty"`
-	ContentHash        string    `json:"content_hash,omitempty"`
-	ContentHashName    string    `json:"content_hash_name,omitempty"`
-	ContentType        string    `json:"content_type,omitempty"`
-	Crc64Hash          string    `json:"crc64_hash,omitempty"`
-	CreatedAt          time.Time `json:"created_at"`
-	DomainId           string    `json:"domain_id"`
-	DownloadUrl        string    `json:"download_url,omitempty"` // Deprecated: download_url 即将废弃
-	EncryptMode        string    `json:"encrypt_mode"`
-	FileExtension      string    `json:"file_extension,omitempty"`
-	Hidden             bool      `json:"hidden"`
-	MimeType           string    `json:"mime_type,omitempty"`
-	Name               string    `json:"name"`
-	ParentFileId       string    `json:"parent_file_id"`
-	PunishFlag         int       `json:"punish_flag,omitempty"`
-	Size               int64     `json:"size,omitempty"`
-	Starred            bool      `json:"starred"`
-	Status             string    `json:"status"`
-	Thumbnail          string    `json:"thumbnail,omitempty"`
-	Type               FileType  `json:"type"`
-	UpdatedAt          time.Time `json:"updated_at"`
-	Url                string    `json:"url,omitempty"`
-	UserMeta           string    `json:"user_meta,omitempty"`
-	SyncFlag           bool      `json:"sync_flag,omitempty"`
-	VideoMediaMetadata struct {
-		Duration              string `json:"duration"`
-		Height                int    `json:"height"`
-		VideoMediaAudioStream []struct {
-			BitRate       string `json:"bit_rate"`
-			ChannelLayout string `json:"channel_layout"`
-			Channels      int    `json:"channels"`
-			CodeName      string `json:"code_name"`
-			Duration      string `json:"duration"`
-			SampleRate    string `json:"sample_rate"`
-		} `json:"video_media_audio_stream,omitempty"`
-		VideoMediaVideoStream []struct {
-			Bitrate  string `json:"bitrate"`
-			Clarity  string `json:"clarity"`
-			CodeName string `json:"code_name"`
-			Duration string `json:"duration"`
-			Fps      string `json:"fps"`
-		} `json:"video_media_video_stream,omitempty"`
-		Width int `json:"width"`
-	} `json:"video_media_metadata,omitempty"`
-	ExFieldsInfo struct {
+	Trashed            bool               `json:"trashed"`
+	DriveId            string             `json:"drive_id"`
+	FileId             string             `json:"file_id"`
+	Category           string             `json:"category,omitempty"`
+	ContentHash        string             `json:"content_hash,omitempty"`
+	ContentHashName    string             `json:"content_hash_name,omitempty"`
+	ContentType        string             `json:"content_type,omitempty"`
+	Crc64Hash          string             `json:"crc64_hash,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	DomainId           string             `json:"domain_id"`
+	DownloadUrl        string             `json:"download_url,omitempty"` // Deprecated: download_url 即将废弃
+	EncryptMode        string             `json:"encrypt_mode"`
+	FileExtension      string             `json:"file_extension,omitempty"`
+	Hidden             bool               `json:"hidden"`
+	MimeType           string             `json:"mime_type,omitempty"`
+	Name               string             `json:"name"`
+	ParentFileId       string             `json:"parent_file_id"`
+	PunishFlag         int                `json:"punish_flag,omitempty"`
+	Size               int64              `json:"size,omitempty"`
+	Starred            bool               `json:"starred"`
+	Status             string             `json:"status"`
+	Thumbnail          string             `json:"thumbnail,omitempty"`
+	Type               FileType           `json:"type"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	TrashedAt          *OptionalTime      `json:"trashed_at,omitempty"`
+	Url                string             `json:"url,omitempty"`
+	UserMeta           UserMeta           `json:"user_meta,omitempty"`
+	Description        string             `json:"description,omitempty"`
+	SyncFlag           bool               `json:"sync_flag,omitempty"`
+	VideoMediaMetadata VideoMediaMetadata `json:"video_media_metadata,omitempty"`
+	ExFieldsInfo       struct {
 	} `json:"ex_fields_info,omitempty"`
 	ErrorInfo
 }
 
 // FileList  获取文件列表
 func (a *Authorize) FileList(option *FileOption) (result FileList, err error) {
+	return a.fileList(option, false)
+}
+
+// fileList 获取文件列表, noCache 为 true 时不读取缓存(结果仍会写入缓存), 用于同步/监听等需要最新数据的场景
+func (a *Authorize) fileList(option *FileOption, noCache bool) (result FileList, err error) {
 	if option == nil {
 		option = NewFileListOption("root", "")
 	}
@@ -80,13 +68,25 @@ func (a *Authorize) FileList(option *FileOption) (result FileList, err error) {
 		option.ParentFileID = "root"
 	}
 
+	cacheKey := metaCacheListKey(a.DriveID, option.ParentFileID, option)
+	if !noCache && a.MetaCache != nil && a.MetaCache.Get(cacheKey, &result) {
+		return result, nil
+	}
+
 	err = a.HttpPost(APIList, option, &result)
 	if err != nil {
 		return result, err
 	}
 
 	if result.Code != "" {
-		err = fmt.Errorf("获取文件列表失败: %s", result.Message)
+		return result, fmt.Errorf("获取文件列表失败: %s", result.Message)
+	}
+
+	if a.MetaCache != nil {
+		a.MetaCache.Set(cacheKey, result)
+		for _, f := range result.Items {
+			a.MetaCache.Set(metaCacheFileKey(a.DriveID, f.FileId), f)
+		}
 	}
 
 	return result, err
@@ -94,19 +94,38 @@ func (a *Authorize) FileList(option *FileOption) (result FileList, err error) {
 
 // File 获取文件信息
 func (a *Authorize) File(option *FileOption) (result FileInfo, err error) {
+	return a.file(option, false)
+}
+
+// file 获取文件信息, noCache 为 true 时不读取缓存(结果仍会写入缓存), 用于修改前需要最新数据的场景
+func (a *Authorize) file(option *FileOption, noCache bool) (result FileInfo, err error) {
 	if option == nil {
 		return result, fmt.Errorf("option is nil")
 	}
 
 	option.SetDriveID(a.DriveID)
 
+	cacheKey := metaCacheFileKey(a.DriveID, option.FileID)
+	if option.FileID == "" {
+		cacheKey = metaCachePathKey(a.DriveID, option.Path)
+	}
+
+	if !noCache && a.MetaCache != nil && a.MetaCache.Get(cacheKey, &result) {
+		return result, nil
+	}
+
 	err = a.HttpPost(APIFile, option, &result)
 	if err != nil {
 		return result, err
 	}
 
 	if result.Code != "" {
-		err = fmt.Errorf("获取文件信息失败: %s", result.Message)
+		return result, fmt.Errorf("获取文件信息失败: %s", result.Message)
+	}
+
+	if a.MetaCache != nil {
+		a.MetaCache.Set(cacheKey, result)
+		a.MetaCache.Set(metaCacheFileKey(a.DriveID, result.FileId), result)
 	}
 
 	return result, err
@@ -122,6 +141,22 @@ func (a *Authorize) Files(options []*FileOption) (result FileList, err error) {
 		options[index].SetDriveID(a.DriveID)
 	}
 
+	if a.MetaCache != nil {
+		cached := make([]FileInfo, 0, len(options))
+		for _, option := range options {
+			var f FileInfo
+			if !a.MetaCache.Get(metaCacheFileKey(a.DriveID, option.FileID), &f) {
+				break
+			}
+			cached = append(cached, f)
+		}
+
+		if len(cached) == len(options) {
+			result.Items = cached
+			return result, nil
+		}
+	}
+
 	err = a.HttpPost(APIFiles, map[string][]*FileOption{
 		"file_list": options,
 	}, &result)
@@ -130,12 +165,21 @@ func (a *Authorize) Files(options []*FileOption) (result FileList, err error) {
 	}
 
 	if result.Code != "" {
-		err = fmt.Errorf("获取文件信息失败: %s", result.Message)
+		return result, fmt.Errorf("获取文件信息失败: %s", result.Message)
+	}
+
+	if a.MetaCache != nil {
+		for _, f := range result.Items {
+			a.MetaCache.Set(metaCacheFileKey(a.DriveID, f.FileId), f)
+		}
 	}
 
 	return result, err
 }
 
+// FileDownloadURLFallbackExpire 下载链接未返回可解析的过期时间时使用的有效期(接口默认 900 秒)
+var FileDownloadURLFallbackExpire = time.Second * 900
+
 type FileDownloadURL struct {
 	URL        string    `json:"url"`
 	Expiration string    `json:"expiration"`
@@ -157,7 +201,14 @@ func (a *Authorize) FileDownloadURL(option *FileOption) (result FileDownloadURL,
 	}
 
 	if result.Code != "" {
-		err = fmt.Errorf("获取文件下载信息失败: %s", result.Message)
+		return result, fmt.Errorf("获取文件下载信息失败: %s", result.Message)
+	}
+
+	result.ExpireTime, err = time.Parse(time.RFC3339Nano, result.Expiration)
+	if err != nil {
+		// 无法解析时不信任请求的有效期(接口实际有效期可能远小于请求值), 按保守的 FileDownloadURLFallbackExpire 估算
+		result.ExpireTime = time.Now().Add(FileDownloadURLFallbackExpire)
+		err = nil
 	}
 
 	return result, err
@@ -169,14 +220,8 @@ func (a *Authorize) FileRename(option *FileOption) (result FileInfo, err error)
 		return result, fmt.Errorf("option is nil")
 	}
 
-	option.SetDriveID(a.DriveID)
-
-	err = a.HttpPost(APIFileUpdate, option, &result)
-	if err != nil {
-		return result, err
-	}
-
-	if result.Code != "" {
+	result, err = a.FileUpdate(option.FileID, NewFileUpdatePatch().SetName(option.Name).SetCheckNameMode(option.CheckNameMode))
+	if err != nil && result.Code != "" {
 		err = fmt.Errorf("重命名失败: %s", result.Message)
 	}
 
@@ -184,25 +229,9 @@ func (a *Authorize) FileRename(option *FileOption) (result FileInfo, err error)
 }
 
 type FileVideoPlayInfo struct {
-	DriveId              string `json:"drive_id"`
-	FileId               string `json:"file_id"`
-	VideoPreviewPlayInfo struct {
-		Category string `json:"category"`
-		Meta     struct {
-			Duration float64 `json:"duration"`
-			Width    int     `json:"width"`
-			Height   int     `json:"height"`
-		} `json:"meta"`
-		LiveTranscodingTaskList []struct {
-			TemplateId     string `json:"template_id"`
-			TemplateName   string `json:"template_name"`
-			TemplateWidth  int    `json:"template_width"`
-			TemplateHeight int    `json:"template_height"`
-			Status         string `json:"status"`
-			Stage          string `json:"stage"`
-			Url            string `json:"url"`
-		} `json:"live_transcoding_task_list"`
-	} `json:"video_preview_play_info"`
+	DriveId              string               `json:"drive_id"`
+	FileId               string               `json:"file_id"`
+	VideoPreviewPlayInfo VideoPreviewPlayInfo `json:"video_preview_play_info"`
 	ErrorInfo
 }
 
@@ -228,6 +257,9 @@ type FileMoveCopyDelTask struct {
 	FileID      string `json:"file_id"`
 	AsyncTaskID string `json:"async_task_id"`
 	Exist       bool   `json:"exist"`
+	FinalName   string `json:"final_name,omitempty"` // 移动/复制后的文件名
+	Conflict    bool   `json:"conflict,omitempty"`   // 目标目录是否存在同名文件
+	Skipped     bool   `json:"skipped,omitempty"`    // 是否因重名跳过
 	ErrorInfo
 }
 
@@ -242,35 +274,151 @@ func (a *Authorize) FileCopy(option *FileOption) (result FileMoveCopyDelTask, er
 }
 
 // FileMoveAndCopy  移动/复制文件
+// 目标目录存在同名文件时按 option.ConflictPolicy 处理, 结果中 FinalName 为最终文件名, Conflict 表示是否发生重名.
+// 不会修改 option
 func (a *Authorize) FileMoveAndCopy(option *FileOption, isMove bool) (result FileMoveCopyDelTask, err error) {
+	if option == nil {
+		return result, fmt.Errorf("option is nil")
+	}
 
-	option.SetDriveID(a.DriveID)
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
 
-	file, err := a.File(option)
+	file, err := a.file(NewFileOption(option.FileID), true)
 	if err != nil {
 		return result, err
 	}
 
+	name := option.NewName
+	if name == "" {
+		name = file.Name
+	}
+
+	if isMove {
+		defer a.invalidateTree([]string{file.FileId}, file.ParentFileId, option.ToParentFileID)
+	} else {
+		defer a.invalidateFiles(nil, option.ToParentFileID)
+	}
+
+	// 未指定新名字时先以 refuse 模式直接请求, 只在接口返回重名时才列出目标目录处理冲突.
+	// new_name 只在原文件名重名时生效, 指定了新名字时接口无法发现新名字的重名, 需要先列出目标目录检查
+	if name == file.Name {
+		result, err = a.fileMoveAndCopy(option, name, isMove, CheckNameModeRefuse)
+		if err != nil || !result.Exist {
+			return a.finishMoveAndCopy(result, err, file, name)
+		}
+	}
+
+	children, err := a.childrenByName(option.ToParentFileID)
+	if err != nil {
+		return result, err
+	}
+
+	existing, exist := children[name]
+	if isMove && exist && existing.FileId == file.FileId {
+		// 移动到原目录且文件名不变
+		result.Exist = false
+		return a.finishMoveAndCopy(result, nil, file, name)
+	}
+
+	finalName := name
+	if exist {
+		switch option.ConflictPolicy {
+		case ConflictPolicyFail:
+			return result, &FileConflictError{ParentFileID: option.ToParentFileID, Name: name, ExistFileID: existing.FileId}
+		case ConflictPolicySkip:
+			result.DriveID = a.DriveID
+			result.FileID = file.FileId
+			result.FinalName = name
+			result.Conflict = true
+			result.Skipped = true
+			return result, nil
+		case ConflictPolicyOverwrite:
+			if _, err = a.FileTrash(NewFileTrashAndDeleteOption(existing.FileId)); err != nil {
+				return result, err
+			}
+		default:
+			finalName, err = conflictFreeName(children, name, file.FileId, option.RenameTemplate)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// 最终名字与原文件名不同时, 原文件名重名则由接口使用 new_name, 否则移动/复制后再重命名
+	mode := CheckNameModeRefuse
+	if finalName != file.Name {
+		mode = CheckNameModeAutoRename
+	}
+
+	result, err = a.fileMoveAndCopy(option, finalName, isMove, mode)
+	result.Conflict = exist
+	if err == nil && result.Exist && mode == CheckNameModeRefuse {
+		// 处理冲突期间目标目录又出现了同名文件
+		return result, &FileConflictError{ParentFileID: option.ToParentFileID, Name: finalName}
+	}
+	return a.finishMoveAndCopy(result, err, file, finalName)
+}
+
+// fileMoveAndCopy 以 mode 模式发起移动/复制请求, 使用 option 的副本
+func (a *Authorize) fileMoveAndCopy(option *FileOption, name string, isMove bool, mode CheckNameMode) (result FileMoveCopyDelTask, err error) {
 	apiURL := APIFileCopy
+	action := "复制"
 	if isMove {
 		apiURL = APIFileMove
+		action = "移动"
 	}
 
-	newName := strings.Join([]string{file.Name, file.FileId[len(file.FileId)-8:]}, "_")
-	option.SetNewName(newName)
+	req := *option
+	req.SetDriveID(a.DriveID)
+	req.SetNewName(name)
+	req.SetCheckNameMode(mode)
 
-	err = a.HttpPost(apiURL, option, &result)
+	err = a.HttpPost(apiURL, &req, &result)
 	if err != nil {
 		return result, err
 	}
 
 	if result.Code != "" {
-		err = fmt.Errorf("移动文件失败: %s", result.Message)
+		err = fmt.Errorf("%s文件失败: %s", action, result.Message)
 	}
 
 	return result, err
 }
 
+// finishMoveAndCopy 记录最终文件名, new_name 仅在重名时生效, 未重名但指定了新名字时需要再重命名一次.
+// 重命名以 refuse 模式进行, 期间目标目录出现同名文件时返回 *FileConflictError
+func (a *Authorize) finishMoveAndCopy(result FileMoveCopyDelTask, err error, file FileInfo, finalName string) (FileMoveCopyDelTask, error) {
+	if err != nil {
+		return result, err
+	}
+
+	result.FinalName = finalName
+	if result.FileID != "" && finalName != file.Name {
+		moved, err := a.file(NewFileOption(result.FileID), true)
+		if err != nil {
+			return result, err
+		}
+
+		if moved.Name != finalName {
+			result.FinalName = moved.Name
+			option := NewFileRenameOption(result.FileID, finalName)
+			option.SetCheckNameMode(CheckNameModeRefuse)
+			renamed, err := a.FileRename(option)
+			if err != nil {
+				return result, err
+			}
+			if renamed.Name != finalName {
+				return result, &FileConflictError{ParentFileID: moved.ParentFileId, Name: finalName}
+			}
+			result.FinalName = renamed.Name
+		}
+	}
+
+	return result, nil
+}
+
 type FileCreate struct {
 	DriveId      string `json:"drive_id"`
 	FileId       string `json:"file_id"`
@@ -343,7 +491,12 @@ func (a *Authorize) FolderCreate(option *FileOption) (result FileCreate, err err
 // fileAndFolderCreate 创建文件和目录
 func (a *Authorize) fileAndFolderCreate(option *FileOption) (result FileCreate, err error) {
 
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
 	option.SetDriveID(a.DriveID)
+	defer a.invalidateFiles(nil, option.ParentFileID)
 
 	err = a.HttpPost(APIFileCreate, option, &result)
 	if err != nil {
@@ -364,6 +517,10 @@ func (a *Authorize) FileUpload(option *FileOption) (result FileInfo, err error)
 	}
 	defer option.OpenFile.Close()
 
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
 	option.SetDriveID(a.DriveID)
 
 	//获取文件分片信息
@@ -372,6 +529,26 @@ func (a *Authorize) FileUpload(option *FileOption) (result FileInfo, err error)
 		return result, err
 	}
 
+	//检查并预留剩余空间
+	var size int64
+	for _, part := range option.PartInfoList {
+		size += part.ParallelSha1Ctx.PartSize
+	}
+
+	reservation, err := a.reserveQuota(size)
+	if err != nil {
+		return result, err
+	}
+	if reservation != nil {
+		defer func() {
+			if err != nil {
+				reservation.Release()
+				return
+			}
+			reservation.Commit()
+		}()
+	}
+
 	//创建文件
 	creatResp, err := a.FileCreate(option)
 	if err != nil {
@@ -396,6 +573,7 @@ func (a *Authorize) FileUpload(option *FileOption) (result FileInfo, err error)
 	}
 
 	//完成
+	defer a.invalidateFiles([]string{creatResp.FileId}, option.ParentFileID)
 	err = a.HttpPost(APIFileComplete, map[string]string{
 		"file_id":   creatResp.FileId,
 		"drive_id":  creatResp.DriveId,
@@ -420,7 +598,12 @@ func (a *Authorize) FileTrash(option *FileOption) (result FileMoveCopyDelTask, e
 		return result, fmt.Errorf("option is nil")
 	}
 
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
 	option.SetDriveID(a.DriveID)
+	defer a.invalidateTree([]string{option.FileID})
 
 	err = a.HttpPost(APIFileTrash, option, &result)
 	if err != nil {
@@ -440,7 +623,12 @@ func (a *Authorize) FileDelete(option *FileOption) (result FileMoveCopyDelTask,
 		return result, fmt.Errorf("option is nil")
 	}
 
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
 	option.SetDriveID(a.DriveID)
+	defer a.invalidateTree([]string{option.FileID})
 
 	err = a.HttpPost(APIFileDelete, option, &result)
 	if err != nil {
@@ -3,6 +3,7 @@ package aliyundrive_open
 import (
 	"os"
 	"strings"
+	"time"
 )
 
 // OrderSortedField 排序字段
@@ -95,6 +96,14 @@ type FileOption struct {
 	PartInfoList        []FileUpdatePartInfo `json:"part_info_list"`                  // 分片上传信息(上传)
 	OpenFile            *os.File             `json:"-"`                               // 文件流(上传)
 	UploadID            string               `json:"upload_id,omitempty"`             // 上传ID(上传)
+	ConflictPolicy      ConflictPolicy       `json:"-"`                               // 重名处理策略(移动/复制)
+	RenameTemplate      string               `json:"-"`                               // 自动重命名模板(移动/复制)
+	GetSubtitleInfo     bool                 `json:"get_subtitle_info,omitempty"`     // 是否返回字幕信息(播放)
+	WithPlayCursor      bool                 `json:"with_play_cursor,omitempty"`      // 是否返回播放进度(播放)
+	PlayCursor          string               `json:"play_cursor,omitempty"`           // 播放进度, 单位秒(播放记录)
+	Duration            string               `json:"duration,omitempty"`              // 视频时长, 单位秒(播放记录)
+	Query               string               `json:"query,omitempty"`                 // 查询语句(搜索必填)
+	ReturnTotalCount    bool                 `json:"return_total_count,omitempty"`    // 是否返回总数(搜索)
 }
 
 // FileUpdatePartInfo 分片上传选项
@@ -140,6 +149,25 @@ func NewFileVideoPlayInfoOption(fileID string) *FileOption {
 	}
 }
 
+// NewVideoPlayCursorOption 创建更新视频播放进度参数
+func NewVideoPlayCursorOption(fileID string, playCursor, duration time.Duration) *FileOption {
+	option := &FileOption{
+		FileID:     fileID,
+		PlayCursor: formatSeconds(playCursor),
+	}
+	if duration > 0 {
+		option.Duration = formatSeconds(duration)
+	}
+	return option
+}
+
+// NewVideoRecentListOption 创建最近播放列表参数
+func NewVideoRecentListOption() *FileOption {
+	return &FileOption{
+		VideoThumbnailWidth: 480,
+	}
+}
+
 // NewFileTrashAndDeleteOption 创建文件删除参数
 func NewFileTrashAndDeleteOption(fileID string) *FileOption {
 	return &FileOption{
@@ -153,6 +181,7 @@ func NewFileMoveAndCopyOption(fileID, toParentFileID string) *FileOption {
 		FileID:         fileID,
 		ToParentFileID: toParentFileID,
 		CheckNameMode:  "auto_rename",
+		ConflictPolicy: ConflictPolicyAutoRename,
 	}
 }
 
@@ -207,6 +236,42 @@ func NewFileListOption(parentFileID, marker string) *FileOption {
 	}
 }
 
+// NewFileSearchOption 创建文件搜索参数, 默认按更新时间倒序
+func NewFileSearchOption(query *SearchQuery, marker string) *FileOption {
+	option := &FileOption{
+		Marker: marker,
+		Limit:  100,
+	}
+	option.SetQuery(query)
+	option.SetSearchOrder(OrderFieldUpdate, OrderSortedDirectionDesc)
+	return option
+}
+
+// NewFileStarredListOption 创建收藏文件列表参数
+func NewFileStarredListOption(marker string) *FileOption {
+	return &FileOption{
+		Marker:         marker,
+		Limit:          100,
+		OrderBy:        OrderFieldUpdate,
+		OrderDirection: OrderSortedDirectionDesc,
+	}
+}
+
+// NewRecycleBinListOption 创建回收站文件列表参数
+func NewRecycleBinListOption(marker string) *FileOption {
+	return &FileOption{
+		Marker: marker,
+		Limit:  100,
+	}
+}
+
+// NewRecycleBinRestoreOption 创建从回收站恢复文件参数
+func NewRecycleBinRestoreOption(fileID string) *FileOption {
+	return &FileOption{
+		FileID: fileID,
+	}
+}
+
 // SetDriveID 设置目录ID
 func (option *FileOption) SetDriveID(driveID string) *FileOption {
 	option.DriveID = driveID
@@ -261,6 +326,18 @@ func (option *FileOption) SetCheckNameMode(checkNameMode CheckNameMode) *FileOpt
 	return option
 }
 
+// SetConflictPolicy 设置移动/复制时的重名处理策略
+func (option *FileOption) SetConflictPolicy(policy ConflictPolicy) *FileOption {
+	option.ConflictPolicy = policy
+	return option
+}
+
+// SetRenameTemplate 设置自动重命名模板, 支持 {base} {ext} {n} {id} 占位符
+func (option *FileOption) SetRenameTemplate(template string) *FileOption {
+	option.RenameTemplate = template
+	return option
+}
+
 // SetMarker 设置分页标记
 func (option *FileOption) SetMarker(marker string) *FileOption {
 	option.Marker = marker
@@ -273,6 +350,19 @@ func (option *FileOption) SetLimit(limit int64) *FileOption {
 	return option
 }
 
+// SetQuery 设置搜索查询条件
+func (option *FileOption) SetQuery(query *SearchQuery) *FileOption {
+	option.Query = query.String()
+	return option
+}
+
+// SetSearchOrder 设置搜索排序, 搜索接口的排序字段和方式合并在 order_by 中, 例如 "updated_at DESC"
+func (option *FileOption) SetSearchOrder(orderBy OrderSortedField, direction OrderSortedDirection) *FileOption {
+	option.OrderBy = OrderSortedField(string(orderBy) + " " + string(direction))
+	option.OrderDirection = ""
+	return option
+}
+
 // SetOrderBy 设置排序字段
 func (option *FileOption) SetOrderBy(orderBy OrderSortedField) *FileOption {
 	option.OrderBy = orderBy
@@ -0,0 +1,232 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadReferer 请求下载链接时携带的 Referer, 部分下载链接不带 Referer 会返回 403
+var DefaultDownloadReferer = "https://www.aliyundrive.com/"
+
+// FileHandler 云盘文件 HTTP 代理, 不暴露 token 的情况下对外提供文件访问
+// 请求带 file_id 参数时按文件ID查找, 否则以请求路径作为云盘文件路径(可配合 http.StripPrefix 使用).
+// 支持 Range 和条件请求, Content-Type/ETag/Last-Modified 分别取自 MimeType/ContentHash/UpdatedAt
+type FileHandler struct {
+	GetAuthorize func() *Authorize // 获取当前授权信息(token 可能会被刷新)
+	URLCache     *DownloadURLCache // 下载链接缓存
+	Referer      string            // 请求下载链接时携带的 Referer
+	Client       *http.Client      // 请求下载链接的 HTTP 客户端
+}
+
+// NewFileHandler 创建云盘文件 HTTP 代理
+func NewFileHandler(getAuthorize func() *Authorize) *FileHandler {
+	return &FileHandler{
+		GetAuthorize: getAuthorize,
+		URLCache:     NewDownloadURLCache(DefaultDownloadURLMargin),
+		Referer:      DefaultDownloadReferer,
+		Client:       &http.Client{},
+	}
+}
+
+func (h *FileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	a := h.GetAuthorize()
+	if a == nil {
+		http.Error(w, "授权信息不可用", http.StatusServiceUnavailable)
+		return
+	}
+
+	option := NewFileOptionByPath(r.URL.Path)
+	if fileID := r.URL.Query().Get("file_id"); fileID != "" {
+		option = NewFileOption(fileID)
+	}
+
+	file, err := a.File(option)
+	if err != nil {
+		// 只有文件不存在时返回 404, 其他错误(网络, 限流, 授权等)不暴露上游信息
+		if isNotFoundCode(file.Code) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "获取文件信息失败", http.StatusBadGateway)
+		return
+	}
+
+	if file.IsDir() {
+		http.Error(w, "不支持访问目录", http.StatusBadRequest)
+		return
+	}
+
+	h.serveFile(w, r, a, file)
+}
+
+func (h *FileHandler) serveFile(w http.ResponseWriter, r *http.Request, a *Authorize, file FileInfo) {
+	header := w.Header()
+	etag := ""
+	if file.ContentHash != "" {
+		etag = `"` + file.ContentHash + `"`
+		header.Set("ETag", etag)
+	}
+
+	modTime := file.UpdatedAt.UTC().Truncate(time.Second)
+	if !file.UpdatedAt.IsZero() {
+		header.Set("Last-Modified", modTime.Format(http.TimeFormat))
+	}
+
+	contentType := file.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Accept-Ranges", "bytes")
+
+	if status := checkPreconditions(r, etag, modTime); status != 0 {
+		if status == http.StatusNotModified {
+			header.Del("Content-Type")
+			w.WriteHeader(status)
+			return
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	if rangeHeader != "" && !ifRangeMatches(r, etag, modTime) {
+		rangeHeader = ""
+	}
+
+	if r.Method == http.MethodHead && rangeHeader == "" {
+		header.Set("Content-Length", strconv.FormatInt(file.Size, 10))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	res, err := h.fetch(r.Context(), a, file, rangeHeader)
+	if err != nil {
+		http.Error(w, "获取下载链接失败", http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+	default:
+		http.Error(w, "下载文件失败: "+res.Status, http.StatusBadGateway)
+		return
+	}
+
+	for _, key := range []string{"Content-Length", "Content-Range"} {
+		if v := res.Header.Get(key); v != "" {
+			header.Set(key, v)
+		}
+	}
+
+	w.WriteHeader(res.StatusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w, res.Body)
+}
+
+// fetch 请求下载链接, 链接失效(403)时刷新后重试一次
+func (h *FileHandler) fetch(ctx context.Context, a *Authorize, file FileInfo, rangeHeader string) (res *http.Response, err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		download, err := h.URLCache.Get(a, file.FileId)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, download.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.Referer != "" {
+			req.Header.Set("Referer", h.Referer)
+		}
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+
+		res, err = h.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode != http.StatusForbidden || attempt > 0 {
+			return res, nil
+		}
+
+		res.Body.Close()
+		h.URLCache.Invalidate(a.DriveID, file.FileId)
+	}
+	return res, nil
+}
+
+// checkPreconditions 处理条件请求, 返回 0 表示继续处理
+func checkPreconditions(r *http.Request, etag string, modTime time.Time) int {
+	if im := r.Header.Get("If-Match"); im != "" {
+		if !etagListMatches(im, etag) {
+			return http.StatusPreconditionFailed
+		}
+	} else if ius := r.Header.Get("If-Unmodified-Since"); ius != "" && !modTime.IsZero() {
+		if t, err := http.ParseTime(ius); err == nil && modTime.After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagListMatches(inm, etag) {
+			return http.StatusNotModified
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modTime.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil && !modTime.After(t) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// ifRangeMatches If-Range 条件是否满足, 不满足时忽略 Range 返回完整内容
+func ifRangeMatches(r *http.Request, etag string, modTime time.Time) bool {
+	ir := r.Header.Get("If-Range")
+	if ir == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, `W/"`) {
+		return etag != "" && ir == etag
+	}
+
+	t, err := http.ParseTime(ir)
+	return err == nil && !modTime.IsZero() && modTime.Equal(t)
+}
+
+// isNotFoundCode 接口错误码是否表示文件不存在, 如 NotFound.File, NotFound.FileId
+func isNotFoundCode(code string) bool {
+	return strings.HasPrefix(code, "NotFound")
+}
+
+func etagListMatches(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return etag != ""
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimPrefix(strings.TrimSpace(item), "W/")
+		if etag != "" && item == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,387 @@
+package aliyundrive_open
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HLSHandler 视频转码播放(HLS)代理
+// 请求参数: file_id 必填; 不带 template_id/height 时返回包含所有可用清晰度的主播放列表,
+// 带 template_id 或 height(选择不超过该高度的最高清晰度) 时返回该清晰度的播放列表, 分片地址改写为经过本服务;
+// 带 segment 时代理对应分片(包括加密 key 和初始化分片). 播放地址过期时自动重新获取播放信息
+type HLSHandler struct {
+	GetAuthorize func() *Authorize // 获取当前授权信息(token 可能会被刷新)
+	Referer      string            // 请求播放地址时携带的 Referer
+	Client       *http.Client      // 请求播放地址的 HTTP 客户端
+
+	mu    sync.Mutex
+	plays map[string]*hlsPlay
+}
+
+// hlsPlay 缓存的播放信息及各清晰度的分片地址
+type hlsPlay struct {
+	info     FileVideoPlayInfo
+	expire   time.Time
+	segments map[string][]string // template_id -> 分片地址
+}
+
+// NewHLSHandler 创建视频转码播放代理
+func NewHLSHandler(getAuthorize func() *Authorize) *HLSHandler {
+	return &HLSHandler{
+		GetAuthorize: getAuthorize,
+		Referer:      DefaultDownloadReferer,
+		Client:       &http.Client{},
+		plays:        make(map[string]*hlsPlay),
+	}
+}
+
+func (h *HLSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	fileID := query.Get("file_id")
+	if fileID == "" {
+		http.Error(w, "file_id 为空", http.StatusBadRequest)
+		return
+	}
+
+	a := h.GetAuthorize()
+	if a == nil {
+		http.Error(w, "授权信息不可用", http.StatusServiceUnavailable)
+		return
+	}
+
+	templateID := query.Get("template_id")
+	height, _ := strconv.Atoi(query.Get("height"))
+
+	if templateID == "" && height == 0 {
+		h.serveMaster(w, r, a, fileID)
+		return
+	}
+
+	play, err := h.play(a, fileID, false)
+	if err != nil {
+		http.Error(w, "获取播放信息失败", http.StatusBadGateway)
+		return
+	}
+
+	template, ok := selectTranscodingTemplate(play.info, templateID, height)
+	if !ok {
+		http.Error(w, "没有可用的转码清晰度", http.StatusNotFound)
+		return
+	}
+
+	if segment := query.Get("segment"); segment != "" {
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			http.Error(w, "segment 参数错误", http.StatusBadRequest)
+			return
+		}
+		h.serveSegment(w, r, a, fileID, template.TemplateId, index)
+		return
+	}
+
+	h.servePlaylist(w, r, a, fileID, template.TemplateId)
+}
+
+// serveMaster 返回包含所有转码完成清晰度的主播放列表
+func (h *HLSHandler) serveMaster(w http.ResponseWriter, r *http.Request, a *Authorize, fileID string) {
+	play, err := h.play(a, fileID, false)
+	if err != nil {
+		http.Error(w, "获取播放信息失败", http.StatusBadGateway)
+		return
+	}
+
+	var b bytes.Buffer
+	b.WriteString("#EXTM3U\n")
+	for _, t := range play.info.VideoPreviewPlayInfo.LiveTranscodingTaskList {
+		if !t.Finished() {
+			continue
+		}
+
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d,NAME=\"%s\"\n", t.Bandwidth(), t.TemplateWidth, t.TemplateHeight, t.TemplateId)
+		b.WriteString(hlsURL(url.Values{"file_id": {fileID}, "template_id": {t.TemplateId}}))
+		b.WriteString("\n")
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(b.Bytes())
+}
+
+// servePlaylist 返回改写分片地址后的播放列表
+func (h *HLSHandler) servePlaylist(w http.ResponseWriter, r *http.Request, a *Authorize, fileID, templateID string) {
+	body, err := h.playlist(r.Context(), a, fileID, templateID, func(i int) string {
+		return hlsURL(url.Values{"file_id": {fileID}, "template_id": {templateID}, "segment": {strconv.Itoa(i)}})
+	})
+	if err != nil {
+		http.Error(w, "获取播放列表失败", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(body)
+}
+
+// serveSegment 代理单个分片, 地址过期时重新获取播放信息后重试
+func (h *HLSHandler) serveSegment(w http.ResponseWriter, r *http.Request, a *Authorize, fileID, templateID string, index int) {
+	var res *http.Response
+	for attempt := 0; attempt < 2; attempt++ {
+		segmentURL, err := h.segmentURL(r.Context(), a, fileID, templateID, index, attempt > 0)
+		if err != nil {
+			http.Error(w, "获取分片地址失败", http.StatusBadGateway)
+			return
+		}
+
+		res, err = h.get(r.Context(), segmentURL, r.Header.Get("Range"))
+		if err != nil {
+			http.Error(w, "下载分片失败", http.StatusBadGateway)
+			return
+		}
+
+		if res.StatusCode != http.StatusForbidden || attempt > 0 {
+			break
+		}
+		res.Body.Close()
+	}
+	defer res.Body.Close()
+
+	for _, key := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges"} {
+		if v := res.Header.Get(key); v != "" {
+			w.Header().Set(key, v)
+		}
+	}
+
+	w.WriteHeader(res.StatusCode)
+	if r.Method != http.MethodHead {
+		_, _ = io.Copy(w, res.Body)
+	}
+}
+
+// play 获取缓存的播放信息, refresh 为 true 或已过期时重新获取
+func (h *HLSHandler) play(a *Authorize, fileID string, refresh bool) (*hlsPlay, error) {
+	key := a.DriveID + "/" + fileID
+
+	h.mu.Lock()
+	play, ok := h.plays[key]
+	h.mu.Unlock()
+
+	if ok && !refresh && time.Now().Before(play.expire) {
+		return play, nil
+	}
+
+	option := NewFileVideoPlayInfoOption(fileID)
+	info, err := a.FileVideoPlayInfo(option)
+	if err != nil {
+		return nil, err
+	}
+
+	play = &hlsPlay{
+		info:     info,
+		expire:   time.Now().Add(time.Duration(option.URLExpireSec)*time.Second - DefaultDownloadURLMargin),
+		segments: make(map[string][]string),
+	}
+
+	h.mu.Lock()
+	h.sweep()
+	h.plays[key] = play
+	h.mu.Unlock()
+
+	return play, nil
+}
+
+// sweep 清理已过期的播放信息, 调用时需持有 h.mu
+func (h *HLSHandler) sweep() {
+	now := time.Now()
+	for key, play := range h.plays {
+		if !now.Before(play.expire) {
+			delete(h.plays, key)
+		}
+	}
+}
+
+// playlist 获取并改写播放列表, segmentURL 根据分片序号生成本服务地址
+func (h *HLSHandler) playlist(ctx context.Context, a *Authorize, fileID, templateID string, segmentURL func(i int) string) ([]byte, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		play, err := h.play(a, fileID, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		template, ok := selectTranscodingTemplate(play.info, templateID, 0)
+		if !ok {
+			return nil, fmt.Errorf("转码清晰度(%s)不可用", templateID)
+		}
+
+		res, err := h.get(ctx, template.Url, "")
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode == http.StatusForbidden && attempt == 0 {
+			continue
+		}
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("获取播放列表失败: %s", res.Status)
+		}
+
+		body, segments, err := rewritePlaylist(data, template.Url, segmentURL)
+		if err != nil {
+			return nil, err
+		}
+
+		h.mu.Lock()
+		play.segments[templateID] = segments
+		h.mu.Unlock()
+
+		return body, nil
+	}
+	return nil, fmt.Errorf("获取播放列表失败")
+}
+
+// segmentURL 获取分片的原始地址, 缓存中没有时重新获取播放列表
+func (h *HLSHandler) segmentURL(ctx context.Context, a *Authorize, fileID, templateID string, index int, refresh bool) (string, error) {
+	key := a.DriveID + "/" + fileID
+
+	if !refresh {
+		h.mu.Lock()
+		play, ok := h.plays[key]
+		var segments []string
+		if ok && time.Now().Before(play.expire) {
+			segments = play.segments[templateID]
+		}
+		h.mu.Unlock()
+
+		if index >= 0 && index < len(segments) {
+			return segments[index], nil
+		}
+	} else {
+		h.mu.Lock()
+		delete(h.plays, key)
+		h.mu.Unlock()
+	}
+
+	if _, err := h.playlist(ctx, a, fileID, templateID, func(i int) string { return "" }); err != nil {
+		return "", err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var segments []string
+	if play, ok := h.plays[key]; ok {
+		segments = play.segments[templateID]
+	}
+	if index < 0 || index >= len(segments) {
+		return "", fmt.Errorf("分片(%d)不存在", index)
+	}
+	return segments[index], nil
+}
+
+func (h *HLSHandler) get(ctx context.Context, rawURL, rangeHeader string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Referer != "" {
+		req.Header.Set("Referer", h.Referer)
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	return h.Client.Do(req)
+}
+
+// rewritePlaylist 将播放列表中的分片地址及 EXT-X-KEY/EXT-X-MAP 的 URI 替换为 segmentURL 生成的地址
+// 返回改写后的播放列表和按顺序排列的原始地址(key 和 map 与分片共用序号)
+func rewritePlaylist(data []byte, playlistURL string, segmentURL func(i int) string) (body []byte, segments []string, err error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var b bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-KEY:") || strings.HasPrefix(line, "#EXT-X-MAP:"):
+			line, err = rewriteURIAttribute(line, base, func(ref string) string {
+				u := segmentURL(len(segments))
+				segments = append(segments, ref)
+				return u
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		case !strings.HasPrefix(line, "#"):
+			ref, err := base.Parse(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			line = segmentURL(len(segments))
+			segments = append(segments, ref.String())
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.Bytes(), segments, scanner.Err()
+}
+
+// rewriteURIAttribute 将标签中的 URI 属性解析为绝对地址后, 替换为 replace 返回的地址
+func rewriteURIAttribute(line string, base *url.URL, replace func(ref string) string) (string, error) {
+	start := strings.Index(line, `URI="`)
+	if start < 0 {
+		return line, nil
+	}
+	start += len(`URI="`)
+
+	end := strings.Index(line[start:], `"`)
+	if end < 0 {
+		return line, nil
+	}
+
+	ref, err := base.Parse(line[start : start+end])
+	if err != nil {
+		return line, err
+	}
+	return line[:start] + replace(ref.String()) + line[start+end:], nil
+}
+
+// selectTranscodingTemplate 选择转码完成的清晰度: 优先匹配 templateID, 否则选择不超过 height 的最高清晰度,
+// 都不满足时选择最低清晰度
+func selectTranscodingTemplate(info FileVideoPlayInfo, templateID string, height int) (template LiveTranscodingTask, ok bool) {
+	if templateID != "" {
+		if template, ok = info.Template(templateID); ok && template.Finished() {
+			return template, true
+		}
+		if height == 0 {
+			return template, false
+		}
+	}
+	return info.BestTemplate(height)
+}
+
+// hlsURL 生成本服务地址, 使用相对地址以兼容 http.StripPrefix 和反向代理下的路径前缀
+func hlsURL(values url.Values) string {
+	return "?" + values.Encode()
+}
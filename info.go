@@ -4,22 +4,24 @@ import "fmt"
 
 // DriveInfo 云盘信息
 type DriveInfo struct {
-	Avatar         string        `json:"avatar"`
-	Email          string        `json:"email"`
-	Phone          string        `json:"phone"`
-	Role           string        `json:"role"`
-	Status         string        `json:"status"`
-	Description    string        `json:"description"`
-	Punishments    []interface{} `json:"punishments"`
-	PunishFlagEnum int           `json:"punishFlagEnum"`
-	UserId         string        `json:"user_id"`
-	DomainId       string        `json:"domain_id"`
-	UserName       string        `json:"user_name"`
-	NickName       string        `json:"nick_name"`
-	DefaultDriveId string        `json:"default_drive_id"`
-	CreatedAt      int64         `json:"created_at"`
-	UpdatedAt      int64         `json:"updated_at"`
-	UserData       struct {
+	Avatar          string        `json:"avatar"`
+	Email           string        `json:"email"`
+	Phone           string        `json:"phone"`
+	Role            string        `json:"role"`
+	Status          string        `json:"status"`
+	Description     string        `json:"description"`
+	Punishments     []interface{} `json:"punishments"`
+	PunishFlagEnum  int           `json:"punishFlagEnum"`
+	UserId          string        `json:"user_id"`
+	DomainId        string        `json:"domain_id"`
+	UserName        string        `json:"user_name"`
+	NickName        string        `json:"nick_name"`
+	DefaultDriveId  string        `json:"default_drive_id"`
+	ResourceDriveId string        `json:"resource_drive_id,omitempty"`
+	BackupDriveId   string        `json:"backup_drive_id,omitempty"`
+	CreatedAt       int64         `json:"created_at"`
+	UpdatedAt       int64         `json:"updated_at"`
+	UserData        struct {
 		BackUpConfig struct {
 			手机备份 struct {
 				FolderId      string `json:"folder_id"`
@@ -49,6 +51,61 @@ func (a *Authorize) DriveInfo() (result DriveInfo, err error) {
 	return result, err
 }
 
+// DriveType 云盘类型
+type DriveType string
+
+const (
+	DriveTypeDefault  DriveType = "default"  // 默认云盘
+	DriveTypeResource DriveType = "resource" // 资源库
+	DriveTypeBackup   DriveType = "backup"   // 备份盘
+)
+
+// DriveID 获取指定类型的云盘ID, 不存在时返回空字符串
+func (info *DriveInfo) DriveID(driveType DriveType) string {
+	switch driveType {
+	case DriveTypeDefault:
+		return info.DefaultDriveId
+	case DriveTypeResource:
+		return info.ResourceDriveId
+	case DriveTypeBackup:
+		return info.BackupDriveId
+	}
+	return ""
+}
+
+// DriveIDs 获取所有存在的云盘ID
+func (info *DriveInfo) DriveIDs() map[DriveType]string {
+	ids := make(map[DriveType]string)
+	for _, t := range []DriveType{DriveTypeDefault, DriveTypeResource, DriveTypeBackup} {
+		if id := info.DriveID(t); id != "" {
+			ids[t] = id
+		}
+	}
+	return ids
+}
+
+// WithDrive 返回指定云盘的授权副本, 文件操作将作用于该云盘, 不会修改原授权信息
+func (a *Authorize) WithDrive(driveID string) *Authorize {
+	authorize := *a
+	authorize.DriveID = driveID
+	return &authorize
+}
+
+// Drive 获取指定类型云盘的授权副本
+func (a *Authorize) Drive(driveType DriveType) (*Authorize, error) {
+	info, err := a.DriveInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	driveID := info.DriveID(driveType)
+	if driveID == "" {
+		return nil, fmt.Errorf("云盘(%s)不存在", driveType)
+	}
+
+	return a.WithDrive(driveID), nil
+}
+
 type SpaceInfo struct {
 	PersonalSpaceInfo struct {
 		UsedSize  int64 `json:"used_size"`
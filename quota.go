@@ -0,0 +1,152 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaCacheTTL 空间信息缓存时间
+var DefaultQuotaCacheTTL = time.Second * 30
+
+// QuotaError 剩余空间不足
+type QuotaError struct {
+	Required  int64 // 需要的空间
+	Available int64 // 剩余空间(已扣除其他上传预留的空间)
+	Reserved  int64 // 其他上传预留的空间
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("云盘剩余空间不足: 需要 %d, 剩余 %d(其他上传预留 %d)", e.Required, e.Available, e.Reserved)
+}
+
+// QuotaGuard 上传前检查剩余空间, 并为进行中的上传预留空间, 避免并发上传合计超出剩余空间
+// 同一账号的所有 Authorize(包括 WithDrive 复制的)应共用同一个 QuotaGuard
+type QuotaGuard struct {
+	TTL time.Duration // 空间信息缓存时间
+
+	mu        sync.Mutex
+	used      int64
+	total     int64
+	fetchedAt time.Time
+	reserved  int64
+	fetching  *quotaFetch // 进行中的空间信息请求, 并发的 Reserve 共用同一次请求
+}
+
+// quotaFetch 一次空间信息请求
+type quotaFetch struct {
+	wg  sync.WaitGroup
+	err error
+}
+
+// NewQuotaGuard 创建空间检查
+func NewQuotaGuard(ttl time.Duration) *QuotaGuard {
+	if ttl <= 0 {
+		ttl = DefaultQuotaCacheTTL
+	}
+	return &QuotaGuard{TTL: ttl}
+}
+
+// QuotaReservation 上传预留的空间
+type QuotaReservation struct {
+	guard *QuotaGuard
+	size  int64
+	once  sync.Once
+}
+
+// Reserve 预留 size 大小的空间, 剩余空间不足时返回 *QuotaError
+func (g *QuotaGuard) Reserve(a *Authorize, size int64) (*QuotaReservation, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.fetchedAt.IsZero() || time.Since(g.fetchedAt) > g.TTL {
+		if err := g.fetch(a); err != nil {
+			return nil, err
+		}
+	}
+
+	available := g.total - g.used - g.reserved
+	if size > available {
+		if available < 0 {
+			available = 0
+		}
+		return nil, &QuotaError{Required: size, Available: available, Reserved: g.reserved}
+	}
+
+	g.reserved += size
+	return &QuotaReservation{guard: g, size: size}, nil
+}
+
+// fetch 获取空间信息, 调用时需持有 g.mu. 请求期间释放锁, 避免阻塞 Commit/Release 和其他账号操作;
+// 已有进行中的请求时等待其结果, 不重复请求
+func (g *QuotaGuard) fetch(a *Authorize) error {
+	if f := g.fetching; f != nil {
+		g.mu.Unlock()
+		f.wg.Wait()
+		g.mu.Lock()
+		return f.err
+	}
+
+	f := &quotaFetch{}
+	f.wg.Add(1)
+	g.fetching = f
+	g.mu.Unlock()
+
+	space, err := a.DriveSpace()
+
+	g.mu.Lock()
+	if err == nil {
+		g.used = space.PersonalSpaceInfo.UsedSize
+		g.total = space.PersonalSpaceInfo.TotalSize
+		g.fetchedAt = time.Now()
+	}
+	f.err = err
+	g.fetching = nil
+	f.wg.Done()
+	return err
+}
+
+// Available 缓存的剩余空间(已扣除预留), 未获取过空间信息时返回 -1
+func (g *QuotaGuard) Available() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.fetchedAt.IsZero() {
+		return -1
+	}
+	return g.total - g.used - g.reserved
+}
+
+// Invalidate 使缓存的空间信息失效, 例如在其他地方删除或上传文件后
+func (g *QuotaGuard) Invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.fetchedAt = time.Time{}
+}
+
+// Commit 上传成功, 预留的空间计入已使用空间
+func (r *QuotaReservation) Commit() {
+	r.once.Do(func() {
+		r.guard.mu.Lock()
+		defer r.guard.mu.Unlock()
+		r.guard.reserved -= r.size
+		r.guard.used += r.size
+	})
+}
+
+// Release 上传失败, 释放预留的空间. 已 Commit 时不做处理
+func (r *QuotaReservation) Release() {
+	r.once.Do(func() {
+		r.guard.mu.Lock()
+		defer r.guard.mu.Unlock()
+		r.guard.reserved -= r.size
+	})
+}
+
+// reserveQuota 设置了 Quota 时为上传预留空间, 未设置时返回 nil
+func (a *Authorize) reserveQuota(size int64) (*QuotaReservation, error) {
+	if a.Quota == nil {
+		return nil, nil
+	}
+	return a.Quota.Reserve(a, size)
+}
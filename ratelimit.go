@@ -0,0 +1,255 @@
+package aliyundrive_open
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EndpointClass 接口分类, 用于限流和判断是否可重试
+type EndpointClass string
+
+const (
+	EndpointClassAuth     EndpointClass = "auth"     // 授权相关
+	EndpointClassRead     EndpointClass = "read"     // 只读查询
+	EndpointClassWrite    EndpointClass = "write"    // 写入/修改
+	EndpointClassDownload EndpointClass = "download" // 下载/播放链接
+)
+
+// Idempotent 该分类的接口是否可以安全重试
+func (c EndpointClass) Idempotent() bool {
+	return c == EndpointClassRead || c == EndpointClassDownload
+}
+
+// endpointClasses 接口地址对应的分类, 未登记的接口按写入处理
+var endpointClassesMu sync.RWMutex
+var endpointClasses = map[string]EndpointClass{
+	APIAuthorizeQrCode:   EndpointClassAuth,
+	APIRefreshToken:      EndpointClassAuth,
+	APIDriveInfo:         EndpointClassRead,
+	APISpaceInfo:         EndpointClassRead,
+	APIList:              EndpointClassRead,
+	APIFile:              EndpointClassRead,
+	APIFiles:             EndpointClassRead,
+	APIFileDownload:      EndpointClassDownload,
+	APIFileVideoPlayInfo: EndpointClassDownload,
+	APIFileTrash:         EndpointClassWrite,
+	APIFileDelete:        EndpointClassWrite,
+	APIFileCreate:        EndpointClassWrite,
+	APIFileComplete:      EndpointClassWrite,
+	APIFileMove:          EndpointClassWrite,
+	APIFileCopy:          EndpointClassWrite,
+	APIFileUpdate:        EndpointClassWrite,
+	APIAsyncTask:         EndpointClassRead,
+	APIRecycleBinList:    EndpointClassRead,
+	APIRecycleBinRestore: EndpointClassWrite,
+	APIBatch:             EndpointClassWrite,
+	APIVideoUpdateRecord: EndpointClassWrite,
+	APIVideoRecentList:   EndpointClassRead,
+	APIFileSearch:        EndpointClassRead,
+	APIFileStarredList:   EndpointClassRead,
+}
+
+// SetEndpointClass 登记接口分类
+func SetEndpointClass(url string, class EndpointClass) {
+	endpointClassesMu.Lock()
+	defer endpointClassesMu.Unlock()
+	endpointClasses[url] = class
+}
+
+// GetEndpointClass 获取接口分类
+func GetEndpointClass(url string) EndpointClass {
+	endpointClassesMu.RLock()
+	defer endpointClassesMu.RUnlock()
+	if class, ok := endpointClasses[url]; ok {
+		return class
+	}
+	return EndpointClassWrite
+}
+
+// DefaultRateLimits 默认每个 token 每类接口每秒请求数
+var DefaultRateLimits = map[EndpointClass]float64{
+	EndpointClassAuth:     2,
+	EndpointClassRead:     10,
+	EndpointClassWrite:    5,
+	EndpointClassDownload: 5,
+}
+
+// DefaultRateLimiter 全局共享限流器, 设置为 nil 关闭限流
+var DefaultRateLimiter = NewRateLimiter(DefaultRateLimits)
+
+// RateLimiter 按 token + 接口分类的令牌桶限流器
+type RateLimiter struct {
+	mu      sync.Mutex
+	limits  map[EndpointClass]float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketIdleTimeout 令牌桶空闲多久后清理(access_token 会定期更换)
+const bucketIdleTimeout = time.Minute * 10
+
+// NewRateLimiter 创建限流器, limits 为每类接口每秒请求数, 小于等于 0 表示不限流
+func NewRateLimiter(limits map[EndpointClass]float64) *RateLimiter {
+	l := &RateLimiter{
+		limits:  make(map[EndpointClass]float64),
+		buckets: make(map[string]*tokenBucket),
+	}
+	for class, rps := range limits {
+		l.limits[class] = rps
+	}
+	return l
+}
+
+// SetLimit 设置某类接口每秒请求数
+func (l *RateLimiter) SetLimit(class EndpointClass, rps float64) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[class] = rps
+	return l
+}
+
+// Wait 阻塞直到允许发起请求
+func (l *RateLimiter) Wait(key string, class EndpointClass) {
+	if delay := l.reserve(key, class); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// reserve 预占一个令牌, 返回需要等待的时间
+func (l *RateLimiter) reserve(key string, class EndpointClass) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rps := l.limits[class]
+	if rps <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	l.sweep(now)
+
+	burst := math.Max(1, rps)
+	bucketKey := string(class) + ":" + key
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[bucketKey] = b
+	}
+
+	if now.After(b.last) {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rps)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rps * float64(time.Second))
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	if len(l.buckets) < 1024 {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTimeout {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// RetryPolicy 重试策略, 退避时间为带随机抖动的指数退避
+type RetryPolicy struct {
+	MaxRetries     int           // 最大重试次数
+	BaseDelay      time.Duration // 首次退避时间
+	MaxDelay       time.Duration // 最大退避时间
+	TransientCodes []string      // 可重试的平台错误码
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Millisecond * 500,
+	MaxDelay:   time.Second * 30,
+	TransientCodes: []string{
+		"ServiceUnavailable",
+		"InternalError",
+	},
+}
+
+// TooManyRequestsCode 平台限流错误码
+const TooManyRequestsCode = "TooManyRequests"
+
+// Backoff 第 attempt 次重试前的退避时间(attempt 从 0 开始)
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// retryable 判断请求是否需要重试
+// 限流(429/TooManyRequests)时请求未被处理, 所有接口均可重试; 其余临时错误仅重试幂等接口
+func (p *RetryPolicy) retryable(class EndpointClass, statusCode int, code string, reqErr error) bool {
+	if statusCode == http.StatusTooManyRequests || code == TooManyRequestsCode {
+		return true
+	}
+
+	if !class.Idempotent() {
+		return false
+	}
+
+	if reqErr != nil || statusCode >= http.StatusInternalServerError {
+		return true
+	}
+
+	for _, c := range p.TransientCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay 第 attempt 次重试前的等待时间, 取退避时间和 Retry-After 中较大的一个, 不超过 MaxDelay
+func (p *RetryPolicy) retryDelay(attempt int, header http.Header) time.Duration {
+	delay := p.Backoff(attempt)
+	if after := retryAfter(header); after > delay {
+		delay = after
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// retryAfter 解析 Retry-After 响应头
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
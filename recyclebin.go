@@ -0,0 +1,177 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OptionalTime 可能为空字符串或 null 的时间字段, 无法解析时为零值
+type OptionalTime struct {
+	time.Time
+}
+
+// Value 返回时间, 为 nil 时返回零值
+func (t *OptionalTime) Value() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+func (t *OptionalTime) UnmarshalJSON(data []byte) error {
+	t.Time = time.Time{}
+	if len(data) < 2 || data[0] != '"' || string(data) == `""` {
+		return nil
+	}
+
+	if parsed, err := time.Parse(`"`+time.RFC3339Nano+`"`, string(data)); err == nil {
+		t.Time = parsed
+	}
+	return nil
+}
+
+func (t OptionalTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return t.Time.MarshalJSON()
+}
+
+// RecycleBinList 获取回收站文件列表(分页)
+func (a *Authorize) RecycleBinList(option *FileOption) (result FileList, err error) {
+	if option == nil {
+		option = NewRecycleBinListOption("")
+	}
+	option.SetDriveID(a.DriveID)
+
+	err = a.HttpPost(APIRecycleBinList, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("获取回收站文件列表失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// RecycleBinItems 获取回收站所有文件
+func (a *Authorize) RecycleBinItems() (items []FileInfo, err error) {
+	option := NewRecycleBinListOption("")
+	for {
+		list, err := a.RecycleBinList(option)
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, list.Items...)
+		if list.NextMarker == "" {
+			return items, nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
+
+// RecycleBinRestore 从回收站恢复文件
+func (a *Authorize) RecycleBinRestore(option *FileOption) (result FileMoveCopyDelTask, err error) {
+	if option == nil {
+		return result, fmt.Errorf("option is nil")
+	}
+
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
+	option.SetDriveID(a.DriveID)
+	defer a.invalidateTree([]string{option.FileID}, "")
+
+	err = a.HttpPost(APIRecycleBinRestore, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("从回收站恢复文件失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// RecycleBinClean 清理回收站结果
+type RecycleBinClean struct {
+	DryRun   bool       `json:"dry_run"`   // 是否仅预览
+	Items    []FileInfo `json:"items"`     // 已删除(预览时为将要删除)的文件
+	Size     int64      `json:"size"`      // 释放空间大小
+	FailedID []string   `json:"failed_id"` // 删除失败的文件ID
+	Skipped  []FileInfo `json:"skipped"`   // 未返回放入回收站时间而跳过的文件(仅 RecycleBinPurge)
+}
+
+// RecycleBinEmpty 清空回收站. dryRun 为 true 时只返回将要删除的文件, 不做删除
+func (a *Authorize) RecycleBinEmpty(dryRun bool) (result RecycleBinClean, err error) {
+	return a.recycleBinClean(dryRun, func(f FileInfo) bool {
+		return true
+	})
+}
+
+// RecycleBinPurge 彻底删除放入回收站超过 retentionDays 天的文件
+// 回收站文件未返回 trashed_at 时无法判断放入时间, 不做删除, 记录在 Skipped 中
+func (a *Authorize) RecycleBinPurge(retentionDays int, dryRun bool) (result RecycleBinClean, err error) {
+	if retentionDays <= 0 {
+		return result, fmt.Errorf("retentionDays 必须大于 0")
+	}
+
+	deadline := time.Now().AddDate(0, 0, -retentionDays)
+	skipped := make([]FileInfo, 0)
+	result, err = a.recycleBinClean(dryRun, func(f FileInfo) bool {
+		trashedAt := f.TrashedAt.Value()
+		if trashedAt.IsZero() {
+			skipped = append(skipped, f)
+			return false
+		}
+		return trashedAt.Before(deadline)
+	})
+	result.Skipped = skipped
+	return result, err
+}
+
+func (a *Authorize) recycleBinClean(dryRun bool, match func(f FileInfo) bool) (result RecycleBinClean, err error) {
+	result.DryRun = dryRun
+
+	if !dryRun {
+		if err = a.requireScopes(ScopeWrite); err != nil {
+			return result, err
+		}
+	}
+
+	items, err := a.RecycleBinItems()
+	if err != nil {
+		return result, err
+	}
+
+	errFileIDs := make([]string, 0)
+	for _, f := range items {
+		if !match(f) {
+			continue
+		}
+
+		if !dryRun {
+			_, err := a.FileDelete(NewFileTrashAndDeleteOption(f.FileId))
+			if err != nil {
+				result.FailedID = append(result.FailedID, f.FileId)
+				errFileIDs = append(errFileIDs, strings.Join([]string{f.FileId, err.Error()}, ":"))
+				continue
+			}
+		}
+
+		result.Items = append(result.Items, f)
+		result.Size += f.Size
+	}
+
+	if len(errFileIDs) > 0 {
+		err = fmt.Errorf("失败信息: %s", strings.Join(errFileIDs, ","))
+	}
+
+	return result, err
+}
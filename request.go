@@ -32,6 +32,14 @@ const (
 	APIFileMove          = APIBase + "/adrive/v1.0/openFile/move"                    //移动文件
 	APIFileCopy          = APIBase + "/adrive/v1.0/openFile/copy"                    //复制文件
 	APIFileUpdate        = APIBase + "/adrive/v1.0/openFile/update"                  //更新文件
+	APIRecycleBinList    = APIBase + "/adrive/v1.0/openFile/recyclebin/list"         //获取回收站文件列表
+	APIRecycleBinRestore = APIBase + "/adrive/v1.0/openFile/recyclebin/restore"      //从回收站恢复文件
+	APIBatch             = APIBase + "/adrive/v1.0/batch"                            //批量操作
+	APIAsyncTask         = APIBase + "/adrive/v1.0/openFile/async_task/get"          //获取异步任务状态
+	APIVideoUpdateRecord = APIBase + "/adrive/v1.0/openFile/video/updateRecord"      //更新视频播放进度
+	APIVideoRecentList   = APIBase + "/adrive/v1.0/openFile/video/recentList"        //获取最近播放列表
+	APIFileSearch        = APIBase + "/adrive/v1.0/openFile/search"                  //搜索文件
+	APIFileStarredList   = APIBase + "/adrive/v1.0/openFile/starredList"             //获取收藏文件列表
 
 )
 
@@ -39,10 +47,10 @@ var RestyHttpClient = NewRestyClient()
 var UserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.0.0 Safari/537.36 Edg/108.0.1462.54"
 var DefaultTimeout = time.Second * 30
 
+// NewRestyClient 创建 HTTP 客户端. 包内请求的重试统一由 DefaultRetryPolicy 处理, 这里不再使用 resty 的重试
 func NewRestyClient() *resty.Client {
 	return resty.New().
 		SetHeader("user-agent", UserAgent).
-		SetRetryCount(3).
 		SetTimeout(DefaultTimeout)
 }
 
@@ -54,13 +62,13 @@ func (a *Authorize) HttpPost(url string, reqData interface{}, result interface{}
 }
 
 func HttpPost(url string, header http.Header, reqData interface{}, result interface{}) error {
-	r := RestyHttpClient.R()
+	var body []byte
 	if reqData != nil {
 		dataJson, err := json.Marshal(reqData)
 		if err != nil {
 			return err
 		}
-		r.SetBody(dataJson)
+		body = dataJson
 	}
 
 	if header == nil {
@@ -68,14 +76,50 @@ func HttpPost(url string, header http.Header, reqData interface{}, result interf
 	}
 
 	header.Set("Content-Type", "application/json;charset=UTF-8")
-	resp, err := r.SetHeaderMultiValues(header).Post(url)
-	if err != nil {
-		return errors.New("请求失败: " + err.Error())
-	}
+	return httpDo(http.MethodPost, url, GetEndpointClass(url), header, body, result)
+}
+
+// httpDo 按 DefaultRateLimiter 限流, 按 DefaultRetryPolicy 重试
+func httpDo(method, url string, class EndpointClass, header http.Header, body []byte, result interface{}) error {
+	limitKey := header.Get("Authorization")
+	policy := DefaultRetryPolicy
+
+	for attempt := 0; ; attempt++ {
+		if DefaultRateLimiter != nil {
+			DefaultRateLimiter.Wait(limitKey, class)
+		}
 
-	err = json.Unmarshal(resp.Body(), result)
-	if err != nil {
-		return errors.New("解析数据失败: " + err.Error())
+		r := RestyHttpClient.R().SetHeaderMultiValues(header)
+		if body != nil {
+			r.SetBody(body)
+		}
+
+		resp, err := r.Execute(method, url)
+
+		var statusCode int
+		var errInfo ErrorInfo
+		if err == nil {
+			statusCode = resp.StatusCode()
+			_ = json.Unmarshal(resp.Body(), &errInfo)
+		}
+
+		if policy != nil && attempt < policy.MaxRetries && policy.retryable(class, statusCode, errInfo.Code, err) {
+			var respHeader http.Header
+			if resp != nil {
+				respHeader = resp.Header()
+			}
+			time.Sleep(policy.retryDelay(attempt, respHeader))
+			continue
+		}
+
+		if err != nil {
+			return errors.New("请求失败: " + err.Error())
+		}
+
+		err = json.Unmarshal(resp.Body(), result)
+		if err != nil {
+			return errors.New("解析数据失败: " + err.Error())
+		}
+		return err
 	}
-	return err
 }
@@ -0,0 +1,86 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeError 授权范围不足
+type ScopeError struct {
+	Required []Scope // 操作需要的授权范围
+	Missing  []Scope // 缺少的授权范围
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("授权范围不足, 缺少: %s", joinCustomString(e.Missing, ","))
+}
+
+// ParseScopes 解析授权范围字符串, 支持逗号和空格分隔
+func ParseScopes(raw string) (scopes []Scope) {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+
+	seen := make(map[Scope]bool)
+	for _, f := range fields {
+		s := Scope(f)
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Scopes 已授权的范围. 为空表示未知(token 接口未返回且未手动设置)
+func (a *Authorize) Scopes() []Scope {
+	return ParseScopes(a.Scope)
+}
+
+// SetScopes 手动设置已授权的范围, 通常与授权时 AuthorizeOption.Scopes 一致
+func (a *Authorize) SetScopes(scopes []Scope) *Authorize {
+	a.Scope = joinCustomString(scopes, ",")
+	return a
+}
+
+// HasScope 是否拥有指定授权范围. 未知授权范围时返回 true, 由接口自行判断
+func (a *Authorize) HasScope(scope Scope) bool {
+	return len(a.MissingScopes(scope)) == 0
+}
+
+// MissingScopes 计算重新授权需要增加的授权范围. 未知授权范围时返回空
+func (a *Authorize) MissingScopes(required ...Scope) (missing []Scope) {
+	granted := a.Scopes()
+	if len(granted) == 0 {
+		return nil
+	}
+
+	grantedSet := make(map[Scope]bool)
+	for _, s := range granted {
+		grantedSet[s] = true
+	}
+
+	for _, r := range required {
+		for _, s := range ParseScopes(r.String()) {
+			if !grantedSet[s] {
+				grantedSet[s] = true
+				missing = append(missing, s)
+			}
+		}
+	}
+	return missing
+}
+
+// ReauthorizeScopes 重新授权时需要申请的完整授权范围(已授权 + 缺少的)
+func (a *Authorize) ReauthorizeScopes(required ...Scope) []Scope {
+	return append(a.Scopes(), a.MissingScopes(required...)...)
+}
+
+// requireScopes 检查授权范围, 不足时返回 *ScopeError
+func (a *Authorize) requireScopes(required ...Scope) error {
+	missing := a.MissingScopes(required...)
+	if len(missing) == 0 {
+		return nil
+	}
+	return &ScopeError{Required: required, Missing: missing}
+}
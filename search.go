@@ -0,0 +1,186 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchTimeLayout 搜索语句中的时间格式(UTC)
+const SearchTimeLayout = "2006-01-02T15:04:05"
+
+// SearchQuery 搜索查询条件, 多个条件之间为 and 关系
+// 例如: NewSearchQuery().NameMatch("合同").Category(FileCategoryDoc).SizeRange(1024, 0)
+// 生成: name match "合同" and category = "doc" and size >= 1024
+type SearchQuery struct {
+	conditions []string
+}
+
+// NewSearchQuery 创建搜索查询条件
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// String 生成搜索语句
+func (q *SearchQuery) String() string {
+	if q == nil {
+		return ""
+	}
+	return strings.Join(q.conditions, " and ")
+}
+
+// Raw 添加自定义条件, 例如 `file_extension = "mp4"`
+func (q *SearchQuery) Raw(condition string) *SearchQuery {
+	if condition != "" {
+		q.conditions = append(q.conditions, condition)
+	}
+	return q
+}
+
+// NameMatch 文件名模糊匹配
+func (q *SearchQuery) NameMatch(name string) *SearchQuery {
+	return q.Raw("name match " + quoteSearchValue(name))
+}
+
+// NameEqual 文件名完全匹配
+func (q *SearchQuery) NameEqual(name string) *SearchQuery {
+	return q.Raw("name = " + quoteSearchValue(name))
+}
+
+// Category 文件分类, 多个分类之间为 or 关系
+func (q *SearchQuery) Category(categories ...FileCategory) *SearchQuery {
+	values := make([]string, 0, len(categories))
+	for _, c := range categories {
+		values = append(values, c.String())
+	}
+	return q.in("category", values)
+}
+
+// FileExtension 文件扩展名(不带点), 多个扩展名之间为 or 关系
+func (q *SearchQuery) FileExtension(extensions ...string) *SearchQuery {
+	values := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		values = append(values, strings.TrimPrefix(ext, "."))
+	}
+	return q.in("file_extension", values)
+}
+
+// Type 文件或目录, FileTypeAll 不添加条件
+func (q *SearchQuery) Type(fileType FileType) *SearchQuery {
+	if fileType == "" || fileType == FileTypeAll {
+		return q
+	}
+	return q.Raw("type = " + quoteSearchValue(string(fileType)))
+}
+
+// SizeRange 文件大小范围(字节, 包含边界), 小于等于 0 表示不限制
+func (q *SearchQuery) SizeRange(min, max int64) *SearchQuery {
+	if min > 0 {
+		q.Raw("size >= " + strconv.FormatInt(min, 10))
+	}
+	if max > 0 {
+		q.Raw("size <= " + strconv.FormatInt(max, 10))
+	}
+	return q
+}
+
+// CreatedRange 创建时间范围, 零值表示不限制
+func (q *SearchQuery) CreatedRange(from, to time.Time) *SearchQuery {
+	return q.timeRange("created_at", from, to)
+}
+
+// UpdatedRange 更新时间范围, 零值表示不限制
+func (q *SearchQuery) UpdatedRange(from, to time.Time) *SearchQuery {
+	return q.timeRange("updated_at", from, to)
+}
+
+// ParentFileID 只搜索指定目录下的文件(不包括子目录), 多个目录之间为 or 关系
+func (q *SearchQuery) ParentFileID(parentFileIDs ...string) *SearchQuery {
+	return q.in("parent_file_id", parentFileIDs)
+}
+
+// Starred 是否收藏
+func (q *SearchQuery) Starred(starred bool) *SearchQuery {
+	return q.Raw("starred = " + strconv.FormatBool(starred))
+}
+
+func (q *SearchQuery) in(field string, values []string) *SearchQuery {
+	switch len(values) {
+	case 0:
+		return q
+	case 1:
+		return q.Raw(field + " = " + quoteSearchValue(values[0]))
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quoteSearchValue(v))
+	}
+	return q.Raw(field + " in [" + strings.Join(quoted, ", ") + "]")
+}
+
+func (q *SearchQuery) timeRange(field string, from, to time.Time) *SearchQuery {
+	if !from.IsZero() {
+		q.Raw(field + " >= " + quoteSearchValue(from.UTC().Format(SearchTimeLayout)))
+	}
+	if !to.IsZero() {
+		q.Raw(field + " <= " + quoteSearchValue(to.UTC().Format(SearchTimeLayout)))
+	}
+	return q
+}
+
+// quoteSearchValue 字符串值加双引号, 转义反斜杠和双引号
+func quoteSearchValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// FileSearchList 文件搜索结果
+type FileSearchList struct {
+	Items      []FileInfo `json:"items"`
+	NextMarker string     `json:"next_marker"`
+	TotalCount int64      `json:"total_count,omitempty"` // ReturnTotalCount 为 true 时返回
+	ErrorInfo
+}
+
+// FileSearch 搜索文件(分页)
+func (a *Authorize) FileSearch(option *FileOption) (result FileSearchList, err error) {
+	if option == nil {
+		return result, fmt.Errorf("option is nil")
+	}
+	option.SetDriveID(a.DriveID)
+
+	err = a.HttpPost(APIFileSearch, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("搜索文件失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// FileSearchItems 搜索文件并自动翻页, max 大于 0 时最多返回 max 个结果
+func (a *Authorize) FileSearchItems(query *SearchQuery, max int) (items []FileInfo, err error) {
+	option := NewFileSearchOption(query, "")
+	for {
+		list, err := a.FileSearch(option)
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, list.Items...)
+		if max > 0 && len(items) >= max {
+			return items[:max], nil
+		}
+
+		if list.NextMarker == "" {
+			return items, nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
@@ -0,0 +1,75 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"time"
+)
+
+// FileStar 收藏文件
+func (a *Authorize) FileStar(fileID string) (result FileInfo, err error) {
+	return a.fileSetStarred(fileID, true)
+}
+
+// FileUnstar 取消收藏文件
+func (a *Authorize) FileUnstar(fileID string) (result FileInfo, err error) {
+	return a.fileSetStarred(fileID, false)
+}
+
+func (a *Authorize) fileSetStarred(fileID string, starred bool) (result FileInfo, err error) {
+	result, err = a.FileUpdate(fileID, NewFileUpdatePatch().SetStarred(starred))
+	if err != nil && result.Code != "" {
+		if starred {
+			err = fmt.Errorf("收藏文件失败: %s", result.Message)
+		} else {
+			err = fmt.Errorf("取消收藏文件失败: %s", result.Message)
+		}
+	}
+
+	return result, err
+}
+
+// FileStarredList 获取收藏文件列表(分页)
+func (a *Authorize) FileStarredList(option *FileOption) (result FileList, err error) {
+	if option == nil {
+		option = NewFileStarredListOption("")
+	}
+	option.SetDriveID(a.DriveID)
+
+	err = a.HttpPost(APIFileStarredList, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("获取收藏文件列表失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// FileStarredItems 获取所有收藏文件
+func (a *Authorize) FileStarredItems() (items []FileInfo, err error) {
+	option := NewFileStarredListOption("")
+	for {
+		list, err := a.FileStarredList(option)
+		if err != nil {
+			return items, err
+		}
+
+		items = append(items, list.Items...)
+		if list.NextMarker == "" {
+			return items, nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
+
+// FileRecentList 获取最近 since 时间内更新过的文件, 按更新时间倒序, limit 大于 0 时最多返回 limit 个
+// 开放平台没有单独的最近文件接口, 通过搜索接口实现
+func (a *Authorize) FileRecentList(since time.Duration, limit int) (items []FileInfo, err error) {
+	query := NewSearchQuery().Type(FileTypeFile)
+	if since > 0 {
+		query.UpdatedRange(time.Now().Add(-since), time.Time{})
+	}
+	return a.FileSearchItems(query, limit)
+}
@@ -0,0 +1,263 @@
+package aliyundrive_open
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SyncAction 同步动作
+type SyncAction string
+
+const (
+	SyncActionUpload      SyncAction = "upload"       // 上传新文件
+	SyncActionUpdate      SyncAction = "update"       // 上传已修改的文件
+	SyncActionMkdir       SyncAction = "mkdir"        // 创建云盘目录
+	SyncActionTrash       SyncAction = "trash"        // 云盘多余文件放入回收站
+	SyncActionDownload    SyncAction = "download"     // 下载新文件/已修改的文件
+	SyncActionDeleteLocal SyncAction = "delete_local" // 删除本地多余文件
+	SyncActionConflict    SyncAction = "conflict"     // 冲突
+)
+
+// SyncChange 同步变更记录
+type SyncChange struct {
+	Path   string     `json:"path"`              // 相对同步根目录的路径, 以 / 分隔
+	Action SyncAction `json:"action"`            // 动作
+	Reason string     `json:"reason,omitempty"`  // 原因
+	Size   int64      `json:"size,omitempty"`    // 文件大小
+	FileID string     `json:"file_id,omitempty"` // 云盘文件ID
+	Error  string     `json:"error,omitempty"`   // 执行失败信息
+}
+
+// SyncReport 同步报告
+type SyncReport struct {
+	DryRun     bool         `json:"dry_run"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Changes    []SyncChange `json:"changes"`
+	Failed     int          `json:"failed"`
+}
+
+func (r *SyncReport) add(change SyncChange, err error) {
+	if err != nil {
+		change.Error = err.Error()
+		r.Failed++
+	}
+	r.Changes = append(r.Changes, change)
+}
+
+// Err 存在失败变更时返回汇总错误
+func (r *SyncReport) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+
+	errs := make([]string, 0, r.Failed)
+	for _, c := range r.Changes {
+		if c.Error != "" {
+			errs = append(errs, strings.Join([]string{c.Path, c.Error}, ":"))
+		}
+	}
+	return fmt.Errorf("失败信息: %s", strings.Join(errs, ","))
+}
+
+// SyncFilter 同步文件过滤. 规则匹配相对路径或文件名, 语法同 path.Match
+type SyncFilter struct {
+	Include []string `json:"include,omitempty"` // 为空时包含所有文件
+	Exclude []string `json:"exclude,omitempty"` // 优先于 Include
+}
+
+func matchSyncPatterns(rel string, patterns []string) bool {
+	name := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded 目录或文件是否被排除
+func (f *SyncFilter) Excluded(rel string) bool {
+	return matchSyncPatterns(rel, f.Exclude)
+}
+
+// Match 文件是否需要同步
+func (f *SyncFilter) Match(rel string) bool {
+	if f.Excluded(rel) {
+		return false
+	}
+	return len(f.Include) == 0 || matchSyncPatterns(rel, f.Include)
+}
+
+// syncEntry 同步树中的一项
+type syncEntry struct {
+	Rel     string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+	File    FileInfo // 云盘文件信息(仅云盘)
+	Partial bool     // 目录下存在被过滤(未遍历)的文件或目录, 删除多余目录时不能整体删除
+}
+
+// markPartial 将 rel 的所有上级目录标记为存在被过滤的内容
+func markPartial(entries map[string]syncEntry, rel string) {
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		e, ok := entries[dir]
+		if !ok || e.Partial {
+			return
+		}
+		e.Partial = true
+		entries[dir] = e
+	}
+}
+
+// remoteTree 递归获取云盘目录树, 以相对路径为键
+func (a *Authorize) remoteTree(folderID string, filter *SyncFilter) (entries map[string]syncEntry, err error) {
+	entries = make(map[string]syncEntry)
+	err = a.walkRemote(folderID, "", filter, entries)
+	return entries, err
+}
+
+func (a *Authorize) walkRemote(folderID, prefix string, filter *SyncFilter, entries map[string]syncEntry) error {
+	option := NewFileListOption(folderID, "")
+	for {
+		list, err := a.fileList(option, true)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range list.Items {
+			rel := path.Join(prefix, f.Name)
+			if filter != nil && filter.Excluded(rel) {
+				markPartial(entries, rel)
+				continue
+			}
+
+			if f.IsDir() {
+				entries[rel] = syncEntry{Rel: rel, IsDir: true, ModTime: f.UpdatedAt, File: f}
+				if err = a.walkRemote(f.FileId, rel, filter, entries); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if filter == nil || filter.Match(rel) {
+				entries[rel] = syncEntry{Rel: rel, Size: f.Size, ModTime: f.UpdatedAt, File: f}
+			} else {
+				markPartial(entries, rel)
+			}
+		}
+
+		if list.NextMarker == "" {
+			return nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
+
+// localTree 递归获取本地目录树, 以相对路径为键
+func localTree(root string, filter *SyncFilter) (entries map[string]syncEntry, err error) {
+	entries = make(map[string]syncEntry)
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if filter != nil && filter.Excluded(rel) {
+			markPartial(entries, rel)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			entries[rel] = syncEntry{Rel: rel, IsDir: true}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || (filter != nil && !filter.Match(rel)) {
+			markPartial(entries, rel)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries[rel] = syncEntry{Rel: rel, Size: info.Size(), ModTime: info.ModTime()}
+		return nil
+	})
+	return entries, err
+}
+
+// fileSha1 计算本地文件 SHA1, 返回大写十六进制(与 ContentHash 一致)
+func fileSha1(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// sameContent 判断本地文件与云盘文件内容是否一致
+// 大小不同视为不同; compareHash 为 true 时比较 SHA1, 否则以修改时间判断
+func sameContent(localPath string, local syncEntry, remote FileInfo, compareHash bool) (same bool, reason string, err error) {
+	if local.Size != remote.Size {
+		return false, "size", nil
+	}
+
+	if compareHash && strings.EqualFold(remote.ContentHashName, "sha1") && remote.ContentHash != "" {
+		sum, err := fileSha1(localPath)
+		if err != nil {
+			return false, "", err
+		}
+		if !strings.EqualFold(sum, remote.ContentHash) {
+			return false, "sha1", nil
+		}
+		return true, "", nil
+	}
+
+	if local.ModTime.Truncate(time.Second).After(remote.UpdatedAt) {
+		return false, "mtime", nil
+	}
+	return true, "", nil
+}
+
+// sortedKeys 按路径排序, 保证父目录先于子项处理
+func sortedKeys(entries map[string]syncEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
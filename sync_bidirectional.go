@@ -0,0 +1,584 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SyncConflictStrategy 双向同步冲突处理策略
+type SyncConflictStrategy string
+
+const (
+	SyncConflictKeepBoth   SyncConflictStrategy = "keep_both"   // 保留两份, 本地文件加后缀后上传, 云盘文件下载到原路径
+	SyncConflictNewestWins SyncConflictStrategy = "newest_wins" // 修改时间较新的一方覆盖另一方
+	SyncConflictLocalWins  SyncConflictStrategy = "local_wins"  // 以本地为准
+)
+
+const (
+	SyncActionRenameLocal SyncAction = "rename_local" // 云盘文件被移动/重命名, 同步移动本地文件
+)
+
+// DefaultSyncConflictSuffix 保留两份时本地冲突文件的默认后缀
+var DefaultSyncConflictSuffix = ".conflict"
+
+// SyncBidirectionalOption 双向同步选项
+type SyncBidirectionalOption struct {
+	LocalDir       string               // 本地目录
+	RemoteFolderID string               // 云盘目录ID
+	StateFile      string               // 同步状态文件
+	SyncFilter                          // 包含/排除规则
+	Strategy       SyncConflictStrategy // 冲突处理策略
+	ConflictSuffix string               // 保留两份时本地冲突文件后缀
+	CompareHash    bool                 // 首次同步两端都存在时比较 SHA1
+	DryRun         bool                 // 只生成报告, 不做修改, 不保存状态
+}
+
+// NewSyncBidirectionalOption 创建双向同步选项, 默认冲突时保留两份
+func NewSyncBidirectionalOption(localDir, remoteFolderID, stateFile string) *SyncBidirectionalOption {
+	if remoteFolderID == "" {
+		remoteFolderID = "root"
+	}
+
+	return &SyncBidirectionalOption{
+		LocalDir:       localDir,
+		RemoteFolderID: remoteFolderID,
+		StateFile:      stateFile,
+		Strategy:       SyncConflictKeepBoth,
+		ConflictSuffix: DefaultSyncConflictSuffix,
+	}
+}
+
+// syncBidirectional 单次双向同步的执行状态
+type syncBidirectional struct {
+	a         *Authorize
+	option    *SyncBidirectionalOption
+	report    *SyncReport
+	local     map[string]syncEntry
+	remote    map[string]syncEntry
+	prev      map[string]SyncStateEntry
+	next      map[string]SyncStateEntry
+	folderIDs map[string]string
+}
+
+// SyncBidirectional 本地目录与云盘目录双向同步
+// 根据状态文件中上次同步时两端的信息判断变化方向, 两端同时修改(或一端修改一端删除)时按 Strategy 处理冲突.
+// 云盘文件被移动/重命名时通过 FileId 识别, 同步移动本地文件而不是重新下载
+func (a *Authorize) SyncBidirectional(option *SyncBidirectionalOption) (report SyncReport, err error) {
+	if option == nil {
+		return report, fmt.Errorf("option is nil")
+	}
+
+	if option.StateFile == "" {
+		return report, fmt.Errorf("StateFile 为空")
+	}
+
+	report.DryRun = option.DryRun
+	report.StartedAt = time.Now()
+	defer func() {
+		report.FinishedAt = time.Now()
+	}()
+
+	if !option.DryRun {
+		if err = a.requireScopes(ScopeWrite); err != nil {
+			return report, err
+		}
+	}
+
+	state, err := LoadSyncState(option.StateFile)
+	if err != nil {
+		return report, err
+	}
+
+	filter := option.SyncFilter
+	filter.Exclude = append(append([]string{}, filter.Exclude...), "*"+PartialDownloadSuffix, "*"+PartialDownloadMetaSuffix)
+
+	local, err := localTree(option.LocalDir, &filter)
+	if err != nil {
+		return report, err
+	}
+
+	remote, err := a.remoteTree(option.RemoteFolderID, &filter)
+	if err != nil {
+		return report, err
+	}
+
+	s := &syncBidirectional{
+		a:         a,
+		option:    option,
+		report:    &report,
+		local:     local,
+		remote:    remote,
+		prev:      state.Entries,
+		next:      make(map[string]SyncStateEntry),
+		folderIDs: map[string]string{"": option.RemoteFolderID},
+	}
+
+	for rel, entry := range remote {
+		if entry.IsDir {
+			s.folderIDs[rel] = entry.File.FileId
+		}
+	}
+
+	s.detectRemoteRenames()
+
+	paths := make(map[string]bool)
+	for rel := range local {
+		paths[rel] = true
+	}
+	for rel := range remote {
+		paths[rel] = true
+	}
+	for rel := range s.prev {
+		paths[rel] = true
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for rel := range paths {
+		sorted = append(sorted, rel)
+	}
+	sort.Strings(sorted)
+
+	pendingDirs := make([]string, 0)
+	for _, rel := range sorted {
+		if s.isDir(rel) {
+			if s.syncDir(rel) {
+				pendingDirs = append(pendingDirs, rel)
+			}
+			continue
+		}
+		s.syncFile(rel)
+	}
+
+	// 子项处理完成后再处理被删除的目录, 从深到浅
+	for i := len(pendingDirs) - 1; i >= 0; i-- {
+		s.syncDeletedDir(pendingDirs[i])
+	}
+
+	if !option.DryRun {
+		state.Entries = s.next
+		if err = state.Save(); err != nil {
+			return report, err
+		}
+	}
+
+	return report, report.Err()
+}
+
+func (s *syncBidirectional) isDir(rel string) bool {
+	if l, ok := s.local[rel]; ok {
+		return l.IsDir
+	}
+	if r, ok := s.remote[rel]; ok {
+		return r.IsDir
+	}
+	return s.prev[rel].IsDir
+}
+
+func (s *syncBidirectional) localPath(rel string) string {
+	return filepath.Join(s.option.LocalDir, filepath.FromSlash(rel))
+}
+
+// detectRemoteRenames 云盘文件 FileId 不变但路径变化时, 移动本地未修改的文件
+func (s *syncBidirectional) detectRemoteRenames() {
+	byID := make(map[string]string)
+	for rel, entry := range s.prev {
+		if !entry.IsDir {
+			byID[entry.FileID] = rel
+		}
+	}
+
+	for _, rel := range sortedKeys(s.remote) {
+		r := s.remote[rel]
+		if r.IsDir {
+			continue
+		}
+
+		if prev, ok := s.prev[rel]; ok && prev.FileID == r.File.FileId {
+			continue
+		}
+
+		oldRel, ok := byID[r.File.FileId]
+		if !ok || oldRel == rel {
+			continue
+		}
+
+		if old, exist := s.remote[oldRel]; exist && old.File.FileId == r.File.FileId {
+			continue
+		}
+
+		l, ok := s.local[oldRel]
+		prev := s.prev[oldRel]
+		if !ok || l.IsDir || prev.localChanged(l) {
+			continue
+		}
+
+		if _, exist := s.local[rel]; exist {
+			continue
+		}
+
+		change := SyncChange{Path: rel, Action: SyncActionRenameLocal, Reason: oldRel, Size: r.Size, FileID: r.File.FileId}
+		if !s.option.DryRun {
+			newPath := s.localPath(rel)
+			err := os.MkdirAll(filepath.Dir(newPath), 0755)
+			if err == nil {
+				err = os.Rename(s.localPath(oldRel), newPath)
+			}
+			if err != nil {
+				s.report.add(change, err)
+				continue
+			}
+		}
+		s.report.add(change, nil)
+
+		l.Rel = rel
+		s.local[rel] = l
+		delete(s.local, oldRel)
+		s.prev[rel] = prev
+		delete(s.prev, oldRel)
+	}
+}
+
+// syncDir 处理目录, 返回 true 表示目录在一端被删除, 需要在子项处理完成后再处理
+func (s *syncBidirectional) syncDir(rel string) bool {
+	_, inLocal := s.local[rel]
+	r, inRemote := s.remote[rel]
+	_, inPrev := s.prev[rel]
+
+	switch {
+	case inLocal && inRemote:
+		s.next[rel] = SyncStateEntry{IsDir: true, FileID: r.File.FileId}
+	case inLocal && !inPrev:
+		if _, err := s.ensureRemoteDir(rel); err == nil {
+			s.next[rel] = SyncStateEntry{IsDir: true, FileID: s.folderIDs[rel]}
+		}
+	case inRemote && !inPrev:
+		change := SyncChange{Path: rel, Action: SyncActionDownload, FileID: r.File.FileId}
+		if !s.option.DryRun {
+			if err := os.MkdirAll(s.localPath(rel), 0755); err != nil {
+				s.report.add(change, err)
+				return false
+			}
+		}
+		s.next[rel] = SyncStateEntry{IsDir: true, FileID: r.File.FileId}
+	case inLocal || inRemote:
+		return true
+	}
+	return false
+}
+
+// syncDeletedDir 目录在一端被删除: 另一端目录下已没有需要保留的内容时同步删除, 否则恢复目录
+func (s *syncBidirectional) syncDeletedDir(rel string) {
+	keep := false
+	for p := range s.next {
+		if strings.HasPrefix(p, rel+"/") {
+			keep = true
+			break
+		}
+	}
+
+	if _, inLocal := s.local[rel]; inLocal {
+		if keep {
+			if _, err := s.ensureRemoteDir(rel); err == nil {
+				s.next[rel] = SyncStateEntry{IsDir: true, FileID: s.folderIDs[rel]}
+			}
+			return
+		}
+
+		change := SyncChange{Path: rel, Action: SyncActionDeleteLocal}
+		if s.option.DryRun {
+			s.report.add(change, nil)
+			return
+		}
+		if err := os.Remove(s.localPath(rel)); err != nil {
+			s.fail(rel, change, err)
+			return
+		}
+		s.report.add(change, nil)
+		return
+	}
+
+	r := s.remote[rel]
+	if keep {
+		if !s.option.DryRun {
+			if err := os.MkdirAll(s.localPath(rel), 0755); err != nil {
+				s.report.add(SyncChange{Path: rel, Action: SyncActionDownload, FileID: r.File.FileId}, err)
+				return
+			}
+		}
+		s.next[rel] = SyncStateEntry{IsDir: true, FileID: r.File.FileId}
+		return
+	}
+
+	s.trash(rel, r)
+}
+
+// syncFile 处理单个文件
+func (s *syncBidirectional) syncFile(rel string) {
+	l, inLocal := s.local[rel]
+	r, inRemote := s.remote[rel]
+	prev, inPrev := s.prev[rel]
+
+	if !inPrev {
+		switch {
+		case inLocal && inRemote:
+			same, _, err := sameContent(s.localPath(rel), l, r.File, s.option.CompareHash)
+			if err != nil {
+				s.report.add(SyncChange{Path: rel, Action: SyncActionConflict}, err)
+				return
+			}
+			if same {
+				s.record(rel, r.File)
+				return
+			}
+			s.resolve(rel, "edit/edit")
+		case inLocal:
+			s.upload(rel, "new")
+		case inRemote:
+			s.download(rel, "new")
+		}
+		return
+	}
+
+	localChanged := inLocal && prev.localChanged(l)
+	remoteChanged := inRemote && prev.remoteChanged(r)
+
+	switch {
+	case inLocal && inRemote:
+		switch {
+		case localChanged && remoteChanged:
+			s.resolve(rel, "edit/edit")
+		case localChanged:
+			s.upload(rel, "local_modified")
+		case remoteChanged:
+			s.download(rel, "remote_modified")
+		default:
+			s.next[rel] = prev
+		}
+	case inLocal:
+		// 云盘已删除
+		if localChanged {
+			s.resolve(rel, "edit/delete")
+			return
+		}
+		s.deleteLocal(rel, "remote_deleted")
+	case inRemote:
+		// 本地已删除
+		if remoteChanged {
+			s.resolve(rel, "delete/edit")
+			return
+		}
+		s.trash(rel, r)
+	}
+}
+
+// resolve 按策略处理冲突, kind 为 本地/云盘 的变化类型
+func (s *syncBidirectional) resolve(rel, kind string) {
+	l, inLocal := s.local[rel]
+	r, inRemote := s.remote[rel]
+
+	strategy := s.option.Strategy
+	if strategy == "" {
+		strategy = SyncConflictKeepBoth
+	}
+
+	s.report.add(SyncChange{Path: rel, Action: SyncActionConflict, Reason: kind + ":" + string(strategy), FileID: r.File.FileId}, nil)
+
+	// 一端删除一端修改
+	if !inLocal || !inRemote {
+		if strategy == SyncConflictLocalWins && !inLocal {
+			s.trash(rel, r)
+			return
+		}
+		if inLocal {
+			s.upload(rel, "conflict")
+		} else {
+			s.download(rel, "conflict")
+		}
+		return
+	}
+
+	switch strategy {
+	case SyncConflictLocalWins:
+		s.upload(rel, "conflict")
+	case SyncConflictNewestWins:
+		if l.ModTime.After(r.File.UpdatedAt) {
+			s.upload(rel, "conflict")
+		} else {
+			s.download(rel, "conflict")
+		}
+	default:
+		s.keepBoth(rel)
+	}
+}
+
+// keepBoth 本地文件加冲突后缀后上传, 云盘文件下载到原路径
+func (s *syncBidirectional) keepBoth(rel string) {
+	suffix := s.option.ConflictSuffix
+	if suffix == "" {
+		suffix = DefaultSyncConflictSuffix
+	}
+
+	ext := path.Ext(rel)
+	conflictRel := strings.TrimSuffix(rel, ext) + suffix + "-" + time.Now().Format("20060102150405") + ext
+
+	l := s.local[rel]
+	change := SyncChange{Path: conflictRel, Action: SyncActionRenameLocal, Reason: rel, Size: l.Size}
+	if !s.option.DryRun {
+		if err := os.Rename(s.localPath(rel), s.localPath(conflictRel)); err != nil {
+			s.report.add(change, err)
+			return
+		}
+	}
+	s.report.add(change, nil)
+
+	l.Rel = conflictRel
+	s.local[conflictRel] = l
+	delete(s.local, rel)
+
+	s.upload(conflictRel, "conflict")
+	s.download(rel, "conflict")
+}
+
+// record 记录同步完成后的状态
+func (s *syncBidirectional) record(rel string, remote FileInfo) {
+	if s.option.DryRun {
+		return
+	}
+
+	entry, err := newSyncStateEntry(remote, s.localPath(rel))
+	if err != nil {
+		s.report.add(SyncChange{Path: rel, Action: SyncActionConflict, Reason: "state"}, err)
+		s.keepPrev(rel)
+		return
+	}
+	s.next[rel] = entry
+}
+
+// keepPrev 操作失败时保留上次的状态, 下次同步重新做出相同的判断
+func (s *syncBidirectional) keepPrev(rel string) {
+	if prev, ok := s.prev[rel]; ok && !s.option.DryRun {
+		s.next[rel] = prev
+	}
+}
+
+// fail 记录失败的操作并保留上次的状态
+func (s *syncBidirectional) fail(rel string, change SyncChange, err error) {
+	s.report.add(change, err)
+	s.keepPrev(rel)
+}
+
+func (s *syncBidirectional) upload(rel, reason string) {
+	l := s.local[rel]
+	r, inRemote := s.remote[rel]
+
+	change := SyncChange{Path: rel, Action: SyncActionUpload, Reason: reason, Size: l.Size}
+	if inRemote {
+		change.Action = SyncActionUpdate
+		change.FileID = r.File.FileId
+	}
+
+	if s.option.DryRun {
+		s.report.add(change, nil)
+		return
+	}
+
+	parentID, err := s.ensureRemoteDir(parentSyncPath(rel))
+	if err != nil {
+		s.fail(rel, change, err)
+		return
+	}
+
+	var uploaded FileInfo
+	if inRemote {
+		uploaded, err = s.a.syncReplaceFile(s.localPath(rel), parentID, path.Base(rel), r.File.FileId)
+	} else {
+		uploaded, err = s.a.syncUploadFile(s.localPath(rel), parentID, path.Base(rel))
+	}
+	if err != nil {
+		s.fail(rel, change, err)
+		return
+	}
+
+	change.FileID = uploaded.FileId
+	s.report.add(change, nil)
+	s.record(rel, uploaded)
+}
+
+func (s *syncBidirectional) download(rel, reason string) {
+	r := s.remote[rel]
+	change := SyncChange{Path: rel, Action: SyncActionDownload, Reason: reason, Size: r.Size, FileID: r.File.FileId}
+	if s.option.DryRun {
+		s.report.add(change, nil)
+		return
+	}
+
+	if err := s.a.downloadFile(r.File, s.localPath(rel)); err != nil {
+		s.fail(rel, change, err)
+		return
+	}
+
+	s.report.add(change, nil)
+	s.record(rel, r.File)
+}
+
+func (s *syncBidirectional) trash(rel string, r syncEntry) {
+	change := SyncChange{Path: rel, Action: SyncActionTrash, Size: r.Size, FileID: r.File.FileId}
+	if s.option.DryRun {
+		s.report.add(change, nil)
+		return
+	}
+
+	if _, err := s.a.FileTrash(NewFileTrashAndDeleteOption(r.File.FileId)); err != nil {
+		s.fail(rel, change, err)
+		return
+	}
+	s.report.add(change, nil)
+}
+
+func (s *syncBidirectional) deleteLocal(rel, reason string) {
+	change := SyncChange{Path: rel, Action: SyncActionDeleteLocal, Reason: reason, Size: s.local[rel].Size}
+	if s.option.DryRun {
+		s.report.add(change, nil)
+		return
+	}
+	if err := os.Remove(s.localPath(rel)); err != nil {
+		s.fail(rel, change, err)
+		return
+	}
+	s.report.add(change, nil)
+}
+
+// ensureRemoteDir 确保云盘目录存在, 返回目录ID
+func (s *syncBidirectional) ensureRemoteDir(rel string) (string, error) {
+	if id, ok := s.folderIDs[rel]; ok {
+		return id, nil
+	}
+
+	change := SyncChange{Path: rel, Action: SyncActionMkdir}
+	if s.option.DryRun {
+		s.report.add(change, nil)
+		s.folderIDs[rel] = ""
+		return "", nil
+	}
+
+	parentID, err := s.ensureRemoteDir(parentSyncPath(rel))
+	if err != nil {
+		return "", err
+	}
+
+	folder, err := s.a.FolderCreate(NewFileCreateOption(parentID, path.Base(rel)).SetCheckNameMode(CheckNameModeRefuse))
+	if err != nil {
+		s.report.add(change, err)
+		return "", err
+	}
+
+	change.FileID = folder.FileId
+	s.report.add(change, nil)
+	s.folderIDs[rel] = folder.FileId
+	return folder.FileId, nil
+}
@@ -0,0 +1,285 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PartialDownloadSuffix 未下载完成的临时文件后缀, 再次同步时从断点继续下载
+const PartialDownloadSuffix = ".adpart"
+
+// PartialDownloadMetaSuffix 临时文件对应的云盘文件信息, 云盘文件变化后不再续传
+const PartialDownloadMetaSuffix = ".adpart.meta"
+
+// partialDownloadMeta 临时文件对应的云盘文件信息
+type partialDownloadMeta struct {
+	FileID      string `json:"file_id"`
+	ContentHash string `json:"content_hash"`
+	Size        int64  `json:"size"`
+}
+
+// partialDownloadMatches 临时文件是否来自同一云盘文件内容
+func partialDownloadMatches(metaPath string, meta partialDownloadMeta) bool {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return false
+	}
+
+	var saved partialDownloadMeta
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return false
+	}
+	return saved == meta
+}
+
+// SyncPullOption 云盘到本地单向同步(镜像)选项
+type SyncPullOption struct {
+	RemoteFolderID string // 云盘目录ID
+	LocalDir       string // 本地目录
+	SyncFilter            // 包含/排除规则
+	CompareHash    bool   // 大小相同时比较 SHA1(需要读取本地文件), 否则比较修改时间
+	DeleteExtra    bool   // 删除本地多余文件, 目录下有被过滤的文件时只处理匹配的文件
+	DryRun         bool   // 只生成报告, 不做修改
+}
+
+// NewSyncPullOption 创建云盘到本地单向同步选项
+func NewSyncPullOption(remoteFolderID, localDir string) *SyncPullOption {
+	if remoteFolderID == "" {
+		remoteFolderID = "root"
+	}
+
+	return &SyncPullOption{
+		RemoteFolderID: remoteFolderID,
+		LocalDir:       localDir,
+	}
+}
+
+// SyncPull 云盘目录单向同步到本地目录
+// 只下载内容有变化的文件, 下载完成后将本地修改时间设置为云盘 UpdatedAt, 未完成的下载会在下次同步时续传
+func (a *Authorize) SyncPull(option *SyncPullOption) (report SyncReport, err error) {
+	if option == nil {
+		return report, fmt.Errorf("option is nil")
+	}
+
+	report.DryRun = option.DryRun
+	report.StartedAt = time.Now()
+	defer func() {
+		report.FinishedAt = time.Now()
+	}()
+
+	filter := option.SyncFilter
+	filter.Exclude = append(append([]string{}, filter.Exclude...), "*"+PartialDownloadSuffix, "*"+PartialDownloadMetaSuffix)
+
+	remote, err := a.remoteTree(option.RemoteFolderID, &filter)
+	if err != nil {
+		return report, err
+	}
+
+	if !option.DryRun {
+		if err = os.MkdirAll(option.LocalDir, 0755); err != nil {
+			return report, err
+		}
+	}
+
+	local, err := localTree(option.LocalDir, &filter)
+	if err != nil && !(option.DryRun && os.IsNotExist(err)) {
+		return report, err
+	}
+
+	for _, rel := range sortedKeys(remote) {
+		entry := remote[rel]
+		localPath := filepath.Join(option.LocalDir, filepath.FromSlash(rel))
+		localEntry, exist := local[rel]
+
+		if exist && localEntry.IsDir != entry.IsDir {
+			report.add(SyncChange{Path: rel, Action: SyncActionConflict, Reason: "type", FileID: entry.File.FileId}, nil)
+			continue
+		}
+
+		if entry.IsDir {
+			if !exist && !option.DryRun {
+				if err := os.MkdirAll(localPath, 0755); err != nil {
+					report.add(SyncChange{Path: rel, Action: SyncActionDownload, FileID: entry.File.FileId}, err)
+				}
+			}
+			continue
+		}
+
+		change := SyncChange{Path: rel, Action: SyncActionDownload, Size: entry.Size, FileID: entry.File.FileId, Reason: "new"}
+		if exist {
+			same, reason, err := samePulledContent(localPath, localEntry, entry.File, option.CompareHash)
+			if err != nil {
+				report.add(change, err)
+				continue
+			}
+
+			if same {
+				continue
+			}
+			change.Reason = reason
+		}
+
+		if option.DryRun {
+			report.add(change, nil)
+			continue
+		}
+
+		report.add(change, a.downloadFile(entry.File, localPath))
+	}
+
+	if option.DeleteExtra {
+		// 从深到浅逐个删除遍历到的文件, 目录只在清空后删除, 保留被过滤的文件(如 *.tmp 和断点续传文件)
+		extra := make([]string, 0)
+		for _, rel := range sortedKeys(local) {
+			if _, ok := remote[rel]; !ok {
+				extra = append(extra, rel)
+			}
+		}
+
+		for i := len(extra) - 1; i >= 0; i-- {
+			rel := extra[i]
+			entry := local[rel]
+			if entry.IsDir && entry.Partial {
+				continue
+			}
+
+			change := SyncChange{Path: rel, Action: SyncActionDeleteLocal, Size: entry.Size}
+			if option.DryRun {
+				report.add(change, nil)
+				continue
+			}
+
+			report.add(change, os.Remove(filepath.Join(option.LocalDir, filepath.FromSlash(rel))))
+		}
+	}
+
+	return report, report.Err()
+}
+
+// samePulledContent 判断本地文件是否与云盘文件一致. 下载时已将本地修改时间设置为 UpdatedAt, 因此要求时间相等
+func samePulledContent(localPath string, local syncEntry, remote FileInfo, compareHash bool) (same bool, reason string, err error) {
+	if local.Size != remote.Size {
+		return false, "size", nil
+	}
+
+	if compareHash && strings.EqualFold(remote.ContentHashName, "sha1") && remote.ContentHash != "" {
+		return sameContent(localPath, local, remote, true)
+	}
+
+	if !local.ModTime.Truncate(time.Second).Equal(remote.UpdatedAt.Truncate(time.Second)) {
+		return false, "mtime", nil
+	}
+	return true, "", nil
+}
+
+// downloadFile 下载云盘文件到本地, 支持断点续传. 完成后设置本地修改时间为云盘 UpdatedAt
+func (a *Authorize) downloadFile(file FileInfo, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+
+	partPath := localPath + PartialDownloadSuffix
+	metaPath := localPath + PartialDownloadMetaSuffix
+	meta := partialDownloadMeta{FileID: file.FileId, ContentHash: file.ContentHash, Size: file.Size}
+
+	flag := os.O_CREATE | os.O_WRONLY
+	if !partialDownloadMatches(metaPath, meta) {
+		// 没有记录或云盘文件已变化, 不能续传
+		flag |= os.O_TRUNC
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(metaPath, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	out, err := os.OpenFile(partPath, flag, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	// 临时文件大于云盘文件, 说明云盘文件已变化, 重新下载
+	if offset > file.Size {
+		if err = out.Truncate(0); err != nil {
+			return err
+		}
+		if offset, err = out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	if offset < file.Size {
+		download, err := a.FileDownloadURL(NewFileDownloadURLOption(file.FileId))
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodGet, download.URL, nil)
+		if err != nil {
+			return err
+		}
+
+		if offset > 0 {
+			req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		switch res.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			// 服务端不支持 Range, 从头下载
+			if err = out.Truncate(0); err != nil {
+				return err
+			}
+			if _, err = out.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("下载文件失败: %s", res.Status)
+		}
+
+		if _, err = io.Copy(out, res.Body); err != nil {
+			return err
+		}
+	}
+
+	stat, err := out.Stat()
+	if err != nil {
+		return err
+	}
+
+	if stat.Size() != file.Size {
+		return fmt.Errorf("下载文件大小不一致: %d/%d", stat.Size(), file.Size)
+	}
+
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(partPath, localPath); err != nil {
+		return err
+	}
+	_ = os.Remove(metaPath)
+
+	return os.Chtimes(localPath, file.UpdatedAt, file.UpdatedAt)
+}
@@ -0,0 +1,233 @@
+package aliyundrive_open
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SyncPushOption 本地到云盘单向同步选项
+type SyncPushOption struct {
+	LocalDir       string // 本地目录
+	RemoteFolderID string // 云盘目录ID
+	SyncFilter            // 包含/排除规则
+	CompareHash    bool   // 大小相同时比较 SHA1(需要读取本地文件), 否则比较修改时间
+	DeleteExtra    bool   // 云盘中本地不存在的文件放入回收站, 目录下有被过滤的文件时只处理匹配的文件
+	DryRun         bool   // 只生成报告, 不做修改
+}
+
+// NewSyncPushOption 创建本地到云盘单向同步选项
+func NewSyncPushOption(localDir, remoteFolderID string) *SyncPushOption {
+	if remoteFolderID == "" {
+		remoteFolderID = "root"
+	}
+
+	return &SyncPushOption{
+		LocalDir:       localDir,
+		RemoteFolderID: remoteFolderID,
+	}
+}
+
+// SyncPush 本地目录单向同步到云盘目录
+// 上传新文件和已修改的文件(先将云盘旧文件放入回收站再上传), 按需将云盘多余文件放入回收站
+func (a *Authorize) SyncPush(option *SyncPushOption) (report SyncReport, err error) {
+	if option == nil {
+		return report, fmt.Errorf("option is nil")
+	}
+
+	report.DryRun = option.DryRun
+	report.StartedAt = time.Now()
+	defer func() {
+		report.FinishedAt = time.Now()
+	}()
+
+	if !option.DryRun {
+		if err = a.requireScopes(ScopeWrite); err != nil {
+			return report, err
+		}
+	}
+
+	local, err := localTree(option.LocalDir, &option.SyncFilter)
+	if err != nil {
+		return report, err
+	}
+
+	remote, err := a.remoteTree(option.RemoteFolderID, &option.SyncFilter)
+	if err != nil {
+		return report, err
+	}
+
+	// 相对路径 -> 云盘目录ID
+	folderIDs := map[string]string{"": option.RemoteFolderID}
+	for rel, entry := range remote {
+		if entry.IsDir {
+			folderIDs[rel] = entry.File.FileId
+		}
+	}
+
+	for _, rel := range sortedKeys(local) {
+		entry := local[rel]
+		parentRel := parentSyncPath(rel)
+		parentID := folderIDs[parentRel]
+		remoteEntry, exist := remote[rel]
+
+		if exist && remoteEntry.IsDir != entry.IsDir {
+			report.add(SyncChange{Path: rel, Action: SyncActionConflict, Reason: "type", FileID: remoteEntry.File.FileId}, nil)
+			continue
+		}
+
+		if entry.IsDir {
+			if exist {
+				continue
+			}
+
+			change := SyncChange{Path: rel, Action: SyncActionMkdir}
+			if option.DryRun {
+				report.add(change, nil)
+				continue
+			}
+
+			if parentID == "" {
+				report.add(change, fmt.Errorf("云盘父目录不存在"))
+				continue
+			}
+
+			folder, err := a.FolderCreate(NewFileCreateOption(parentID, path.Base(rel)).SetCheckNameMode(CheckNameModeRefuse))
+			if err == nil {
+				folderIDs[rel] = folder.FileId
+				change.FileID = folder.FileId
+			}
+			report.add(change, err)
+			continue
+		}
+
+		localPath := filepath.Join(option.LocalDir, filepath.FromSlash(rel))
+		change := SyncChange{Path: rel, Action: SyncActionUpload, Size: entry.Size}
+		if exist {
+			same, reason, err := sameContent(localPath, entry, remoteEntry.File, option.CompareHash)
+			if err != nil {
+				report.add(change, err)
+				continue
+			}
+
+			if same {
+				continue
+			}
+
+			change.Action = SyncActionUpdate
+			change.Reason = reason
+			change.FileID = remoteEntry.File.FileId
+		}
+
+		if option.DryRun {
+			report.add(change, nil)
+			continue
+		}
+
+		if parentID == "" {
+			report.add(change, fmt.Errorf("云盘父目录不存在"))
+			continue
+		}
+
+		var uploaded FileInfo
+		if exist {
+			uploaded, err = a.syncReplaceFile(localPath, parentID, path.Base(rel), remoteEntry.File.FileId)
+		} else {
+			uploaded, err = a.syncUploadFile(localPath, parentID, path.Base(rel))
+		}
+		if err == nil {
+			change.FileID = uploaded.FileId
+		}
+		report.add(change, err)
+	}
+
+	if option.DeleteExtra {
+		trashed := make([]string, 0)
+		for _, rel := range sortedKeys(remote) {
+			if _, ok := local[rel]; ok || underSyncPath(rel, trashed) {
+				continue
+			}
+
+			entry := remote[rel]
+			if entry.IsDir && entry.Partial {
+				// 目录下有被过滤的文件, 只逐个处理遍历到的文件, 保留目录
+				continue
+			}
+
+			change := SyncChange{Path: rel, Action: SyncActionTrash, Size: entry.Size, FileID: entry.File.FileId}
+			if entry.IsDir {
+				trashed = append(trashed, rel)
+			}
+
+			if option.DryRun {
+				report.add(change, nil)
+				continue
+			}
+
+			_, err := a.FileTrash(NewFileTrashAndDeleteOption(entry.File.FileId))
+			report.add(change, err)
+		}
+	}
+
+	return report, report.Err()
+}
+
+// syncUploadFile 上传单个本地文件
+func (a *Authorize) syncUploadFile(localPath, parentID, name string) (result FileInfo, err error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return result, err
+	}
+
+	option := NewFileUploadOption(parentID, name, f)
+	option.SetCheckNameMode(CheckNameModeRefuse)
+	return a.FileUpload(option)
+}
+
+// syncReplaceFile 用本地文件替换云盘文件: 先以临时文件名上传, 成功后再将旧文件放入回收站并重命名,
+// 上传失败时云盘上的旧文件保持不变
+func (a *Authorize) syncReplaceFile(localPath, parentID, name, oldFileID string) (result FileInfo, err error) {
+	suffix, err := secureRandomString(4)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = a.syncUploadFile(localPath, parentID, name+".adsync-"+suffix)
+	if err != nil {
+		return result, err
+	}
+
+	if _, err = a.FileTrash(NewFileTrashAndDeleteOption(oldFileID)); err != nil {
+		_, _ = a.FileTrash(NewFileTrashAndDeleteOption(result.FileId))
+		return result, err
+	}
+
+	if _, err = a.FileRename(NewFileRenameOption(result.FileId, name)); err != nil {
+		return result, fmt.Errorf("旧文件已放入回收站, 新文件重命名失败(%s): %s", result.Name, err)
+	}
+
+	result.Name = name
+	return result, nil
+}
+
+// parentSyncPath 获取父目录相对路径, 根目录为空字符串
+func parentSyncPath(rel string) string {
+	parent := path.Dir(rel)
+	if parent == "." {
+		return ""
+	}
+	return parent
+}
+
+// underSyncPath rel 是否位于 dirs 中的某个目录下
+func underSyncPath(rel string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncStateEntry 双向同步状态中单个路径上次同步完成时两端的信息
+type SyncStateEntry struct {
+	IsDir           bool      `json:"is_dir,omitempty"`
+	FileID          string    `json:"file_id"`
+	RemoteHash      string    `json:"remote_hash,omitempty"`
+	RemoteSize      int64     `json:"remote_size,omitempty"`
+	RemoteUpdatedAt time.Time `json:"remote_updated_at"`
+	LocalSize       int64     `json:"local_size,omitempty"`
+	LocalModTime    time.Time `json:"local_mod_time"`
+}
+
+// SyncState 双向同步状态, 以相对路径为键, 保存为 JSON 文件
+type SyncState struct {
+	Entries map[string]SyncStateEntry `json:"entries"`
+
+	file string
+}
+
+// LoadSyncState 读取同步状态文件, 文件不存在时返回空状态
+func LoadSyncState(file string) (state *SyncState, err error) {
+	state = &SyncState{
+		Entries: make(map[string]SyncStateEntry),
+		file:    file,
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	if err = json.Unmarshal(data, state); err != nil {
+		return state, err
+	}
+
+	if state.Entries == nil {
+		state.Entries = make(map[string]SyncStateEntry)
+	}
+	return state, nil
+}
+
+// Save 保存同步状态, 先写临时文件再替换, 避免中断时损坏
+func (s *SyncState) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return err
+	}
+
+	tmp := s.file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
+
+// localChanged 本地文件相对上次同步是否有变化
+func (e *SyncStateEntry) localChanged(local syncEntry) bool {
+	return local.Size != e.LocalSize || !local.ModTime.Truncate(time.Second).Equal(e.LocalModTime.Truncate(time.Second))
+}
+
+// remoteChanged 云盘文件相对上次同步是否有变化
+func (e *SyncStateEntry) remoteChanged(remote syncEntry) bool {
+	f := remote.File
+	if f.FileId != e.FileID || f.Size != e.RemoteSize {
+		return true
+	}
+
+	if f.ContentHash != "" && e.RemoteHash != "" {
+		return f.ContentHash != e.RemoteHash
+	}
+	return !f.UpdatedAt.Equal(e.RemoteUpdatedAt)
+}
+
+// newSyncStateEntry 根据两端信息生成同步状态
+func newSyncStateEntry(remote FileInfo, localPath string) (entry SyncStateEntry, err error) {
+	stat, err := os.Stat(localPath)
+	if err != nil {
+		return entry, err
+	}
+
+	return SyncStateEntry{
+		IsDir:           stat.IsDir(),
+		FileID:          remote.FileId,
+		RemoteHash:      remote.ContentHash,
+		RemoteSize:      remote.Size,
+		RemoteUpdatedAt: remote.UpdatedAt,
+		LocalSize:       stat.Size(),
+		LocalModTime:    stat.ModTime(),
+	}, nil
+}
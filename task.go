@@ -0,0 +1,112 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AsyncTaskState 异步任务状态
+type AsyncTaskState string
+
+const (
+	AsyncTaskStateSucceed        AsyncTaskState = "Succeed"        // 成功
+	AsyncTaskStateRunning        AsyncTaskState = "Running"        // 执行中
+	AsyncTaskStateFailed         AsyncTaskState = "Failed"         // 失败
+	AsyncTaskStatePartialSucceed AsyncTaskState = "PartialSucceed" // 部分成功
+)
+
+// AsyncTaskPollInterval 等待异步任务时的首次轮询间隔, 之后按两倍递增至 AsyncTaskMaxPollInterval
+var AsyncTaskPollInterval = time.Second
+
+// AsyncTaskMaxPollInterval 等待异步任务时的最大轮询间隔
+var AsyncTaskMaxPollInterval = time.Second * 10
+
+// AsyncTask 异步任务信息
+type AsyncTask struct {
+	AsyncTaskID string         `json:"async_task_id"`
+	State       AsyncTaskState `json:"state"`
+	ErrorInfo
+}
+
+// Done 任务是否已结束, 执行中以外的状态都视为已结束
+func (t *AsyncTask) Done() bool {
+	return t.State != AsyncTaskStateRunning
+}
+
+// AsyncTaskError 异步任务未成功结束(失败, 部分成功或未知状态)
+type AsyncTaskError struct {
+	Task AsyncTask
+}
+
+func (e *AsyncTaskError) Error() string {
+	if e.Task.State != AsyncTaskStateFailed {
+		return fmt.Sprintf("异步任务(%s)未成功结束, 状态: %s", e.Task.AsyncTaskID, e.Task.State)
+	}
+	if e.Task.Message != "" {
+		return fmt.Sprintf("异步任务(%s)执行失败: %s", e.Task.AsyncTaskID, e.Task.Message)
+	}
+	return fmt.Sprintf("异步任务(%s)执行失败", e.Task.AsyncTaskID)
+}
+
+// AsyncTask 获取异步任务状态
+func (a *Authorize) AsyncTask(asyncTaskID string) (result AsyncTask, err error) {
+	if asyncTaskID == "" {
+		return result, fmt.Errorf("asyncTaskID 为空")
+	}
+
+	err = a.HttpPost(APIAsyncTask, map[string]string{
+		"async_task_id": asyncTaskID,
+	}, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("获取异步任务状态失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// WaitAsyncTask 轮询等待异步任务结束, 只在执行中时继续轮询. 任务失败, 部分成功或状态未知时返回 *AsyncTaskError
+func (a *Authorize) WaitAsyncTask(ctx context.Context, asyncTaskID string) (result AsyncTask, err error) {
+	interval := AsyncTaskPollInterval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+
+		result, err = a.AsyncTask(asyncTaskID)
+		if err != nil {
+			return result, err
+		}
+
+		switch result.State {
+		case AsyncTaskStateSucceed:
+			return result, nil
+		case AsyncTaskStateRunning:
+		default:
+			return result, &AsyncTaskError{Task: result}
+		}
+
+		timer.Reset(interval)
+		if interval *= 2; interval > AsyncTaskMaxPollInterval {
+			interval = AsyncTaskMaxPollInterval
+		}
+	}
+}
+
+// Wait 等待移动/复制/删除任务完成. 未返回 AsyncTaskID 表示操作已同步完成
+func (t *FileMoveCopyDelTask) Wait(ctx context.Context, a *Authorize) (result AsyncTask, err error) {
+	if t.AsyncTaskID == "" {
+		result.State = AsyncTaskStateSucceed
+		return result, nil
+	}
+	return a.WaitAsyncTask(ctx, t.AsyncTaskID)
+}
@@ -0,0 +1,203 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// UserMeta 文件自定义元数据, 接口中以 JSON 字符串保存
+// 非 JSON 对象格式的旧数据保存在空字符串键下
+type UserMeta map[string]string
+
+// Clone 复制元数据
+func (m UserMeta) Clone() UserMeta {
+	if m == nil {
+		return nil
+	}
+
+	clone := make(UserMeta, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// Keys 按字母顺序返回所有键
+func (m UserMeta) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m UserMeta) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte(`""`), nil
+	}
+
+	data, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(data))
+}
+
+func (m *UserMeta) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = nil
+		return nil
+	}
+
+	raw := data
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*m = nil
+			return nil
+		}
+		raw = []byte(s)
+	}
+
+	values := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &values); err != nil {
+		*m = UserMeta{"": string(raw)}
+		return nil
+	}
+
+	meta := make(UserMeta, len(values))
+	for k, v := range values {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+		meta[k] = s
+	}
+	*m = meta
+	return nil
+}
+
+// FileUpdatePatch 文件更新内容, 只发送设置过的字段
+type FileUpdatePatch struct {
+	Name          *string       `json:"name,omitempty"`            // 文件名
+	CheckNameMode CheckNameMode `json:"check_name_mode,omitempty"` // 重命名时检查文件名模式
+	Starred       *bool         `json:"starred,omitempty"`         // 是否收藏
+	Hidden        *bool         `json:"hidden,omitempty"`          // 是否隐藏
+	Description   *string       `json:"description,omitempty"`     // 文件描述
+	UserMeta      *UserMeta     `json:"user_meta,omitempty"`       // 自定义元数据(整体替换)
+}
+
+// NewFileUpdatePatch 创建文件更新内容
+func NewFileUpdatePatch() *FileUpdatePatch {
+	return &FileUpdatePatch{}
+}
+
+// SetName 设置文件名
+func (p *FileUpdatePatch) SetName(name string) *FileUpdatePatch {
+	p.Name = &name
+	return p
+}
+
+// SetCheckNameMode 设置重命名时检查文件名模式
+func (p *FileUpdatePatch) SetCheckNameMode(mode CheckNameMode) *FileUpdatePatch {
+	p.CheckNameMode = mode
+	return p
+}
+
+// SetStarred 设置是否收藏
+func (p *FileUpdatePatch) SetStarred(starred bool) *FileUpdatePatch {
+	p.Starred = &starred
+	return p
+}
+
+// SetHidden 设置是否隐藏
+func (p *FileUpdatePatch) SetHidden(hidden bool) *FileUpdatePatch {
+	p.Hidden = &hidden
+	return p
+}
+
+// SetDescription 设置文件描述
+func (p *FileUpdatePatch) SetDescription(description string) *FileUpdatePatch {
+	p.Description = &description
+	return p
+}
+
+// SetUserMeta 设置自定义元数据, 会替换原有的全部元数据
+func (p *FileUpdatePatch) SetUserMeta(meta UserMeta) *FileUpdatePatch {
+	if meta == nil {
+		meta = UserMeta{}
+	}
+	p.UserMeta = &meta
+	return p
+}
+
+// Empty 是否没有设置任何字段
+func (p *FileUpdatePatch) Empty() bool {
+	return p.Name == nil && p.Starred == nil && p.Hidden == nil && p.Description == nil && p.UserMeta == nil
+}
+
+// FileUpdate 更新文件信息
+func (a *Authorize) FileUpdate(fileID string, patch *FileUpdatePatch) (result FileInfo, err error) {
+	if patch == nil || patch.Empty() {
+		return result, fmt.Errorf("patch is empty")
+	}
+
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
+	option := struct {
+		DriveID string `json:"drive_id"`
+		FileID  string `json:"file_id"`
+		*FileUpdatePatch
+	}{
+		DriveID:         a.DriveID,
+		FileID:          fileID,
+		FileUpdatePatch: patch,
+	}
+	defer a.invalidateFiles([]string{fileID})
+
+	err = a.HttpPost(APIFileUpdate, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("更新文件信息失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// FileUpdateUserMeta 合并更新文件自定义元数据, 值为空字符串时删除该键
+// 读取时不使用缓存, 避免覆盖其他地方的修改. 原有元数据不是 JSON 对象时拒绝合并, 避免改写原数据
+func (a *Authorize) FileUpdateUserMeta(fileID string, changes map[string]string) (result FileInfo, err error) {
+	file, err := a.file(NewFileOption(fileID), true)
+	if err != nil {
+		return result, err
+	}
+
+	if _, ok := file.UserMeta[""]; ok {
+		return result, fmt.Errorf("文件(%s)的自定义元数据不是 JSON 对象, 无法合并更新", fileID)
+	}
+
+	meta := file.UserMeta.Clone()
+	if meta == nil {
+		meta = UserMeta{}
+	}
+
+	for k, v := range changes {
+		if v == "" {
+			delete(meta, k)
+			continue
+		}
+		meta[k] = v
+	}
+
+	return a.FileUpdate(fileID, NewFileUpdatePatch().SetUserMeta(meta))
+}
@@ -0,0 +1,191 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"time"
+)
+
+// UsageNode 目录树中的一项, 目录的 Size 为其下所有文件大小之和
+type UsageNode struct {
+	FileID    string       `json:"file_id"`
+	Name      string       `json:"name"`
+	Path      string       `json:"path"` // 相对分析目录的路径
+	IsDir     bool         `json:"is_dir"`
+	Category  FileCategory `json:"category,omitempty"`
+	Size      int64        `json:"size"`
+	Files     int64        `json:"files,omitempty"`   // 目录下文件数(包括子目录)
+	Folders   int64        `json:"folders,omitempty"` // 目录下目录数(包括子目录)
+	UpdatedAt time.Time    `json:"updated_at"`
+	Children  []*UsageNode `json:"children,omitempty"` // 按大小从大到小排序
+}
+
+// UsageCategory 某一文件分类的空间占用
+type UsageCategory struct {
+	Size  int64 `json:"size"`
+	Files int64 `json:"files"`
+}
+
+// UsageReport 空间占用分析结果
+type UsageReport struct {
+	Root       *UsageNode                     `json:"root"`
+	Categories map[FileCategory]UsageCategory `json:"categories"`
+	TopFiles   []*UsageNode                   `json:"top_files"`
+	TopFolders []*UsageNode                   `json:"top_folders"`
+	ScannedAt  time.Time                      `json:"scanned_at"`
+}
+
+// AnalyzeUsage 分析目录(包括子目录)的空间占用, folderID 为空时分析根目录, topN 为返回的最大文件和目录数量
+func (a *Authorize) AnalyzeUsage(folderID string, topN int) (result UsageReport, err error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	root := &UsageNode{FileID: folderID, Name: "/", IsDir: true}
+	if folderID != "root" {
+		folder, err := a.File(NewFileOption(folderID))
+		if err != nil {
+			return result, err
+		}
+		root.Name = folder.Name
+		root.UpdatedAt = folder.UpdatedAt
+	}
+
+	result.Categories = make(map[FileCategory]UsageCategory)
+	if err = a.walkUsage(root, result.Categories); err != nil {
+		return result, err
+	}
+
+	result.Root = root
+	result.TopFiles = root.Top(topN, false)
+	result.TopFolders = root.Top(topN, true)
+	result.ScannedAt = time.Now()
+	return result, nil
+}
+
+func (a *Authorize) walkUsage(node *UsageNode, categories map[FileCategory]UsageCategory) error {
+	option := NewFileListOption(node.FileID, "")
+	for {
+		list, err := a.fileList(option, true)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range list.Items {
+			child := &UsageNode{
+				FileID:    f.FileId,
+				Name:      f.Name,
+				Path:      path.Join(node.Path, f.Name),
+				IsDir:     f.IsDir(),
+				UpdatedAt: f.UpdatedAt,
+			}
+			node.Children = append(node.Children, child)
+
+			if child.IsDir {
+				if err = a.walkUsage(child, categories); err != nil {
+					return err
+				}
+				node.Folders += child.Folders + 1
+				node.Files += child.Files
+				node.Size += child.Size
+				continue
+			}
+
+			child.Size = f.Size
+			child.Category = FileCategory(f.Category)
+			if child.Category == "" {
+				child.Category = FileCategoryOthers
+			}
+
+			c := categories[child.Category]
+			c.Size += f.Size
+			c.Files++
+			categories[child.Category] = c
+
+			node.Files++
+			node.Size += f.Size
+		}
+
+		if list.NextMarker == "" {
+			break
+		}
+		option.SetMarker(list.NextMarker)
+	}
+
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		return node.Children[i].Size > node.Children[j].Size
+	})
+	return nil
+}
+
+// Top 返回子树中最大的 n 个文件(folders 为 false)或目录(folders 为 true), 不包括自身. n 小于等于 0 时返回全部
+func (node *UsageNode) Top(n int, folders bool) []*UsageNode {
+	items := make([]*UsageNode, 0)
+	node.Walk(func(item *UsageNode) {
+		if item != node && item.IsDir == folders {
+			items = append(items, item)
+		}
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Size > items[j].Size
+	})
+
+	if n > 0 && len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// Walk 先序遍历子树
+func (node *UsageNode) Walk(fn func(item *UsageNode)) {
+	fn(node)
+	for _, child := range node.Children {
+		child.Walk(fn)
+	}
+}
+
+// JSON 以 JSON 格式输出
+func (r *UsageReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// WriteNcdu 输出 ncdu 导出格式, 可使用 ncdu -f 离线浏览
+func (r *UsageReport) WriteNcdu(w io.Writer) error {
+	if r.Root == nil {
+		return nil
+	}
+
+	header := map[string]interface{}{
+		"progname":  "aliyundrive_open",
+		"progver":   "1.0",
+		"timestamp": r.ScannedAt.Unix(),
+	}
+
+	return json.NewEncoder(w).Encode([]interface{}{1, 0, header, ncduNode(r.Root)})
+}
+
+// ncduNode 目录为数组, 第一项为目录信息, 其后为文件信息或子目录数组
+func ncduNode(node *UsageNode) interface{} {
+	info := map[string]interface{}{
+		"name": node.Name,
+	}
+	if !node.UpdatedAt.IsZero() {
+		info["mtime"] = node.UpdatedAt.Unix()
+	}
+
+	if !node.IsDir {
+		info["asize"] = node.Size
+		info["dsize"] = node.Size
+		return info
+	}
+
+	items := make([]interface{}, 0, len(node.Children)+1)
+	items = append(items, info)
+	for _, child := range node.Children {
+		items = append(items, ncduNode(child))
+	}
+	return items
+}
@@ -1,6 +1,8 @@
 package aliyundrive_open
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -19,6 +21,15 @@ func randomString(n int) string {
 	return string(b)
 }
 
+// secureRandomString 使用 crypto/rand 生成 n 字节的随机十六进制字符串, 用于 state 等需要不可预测的场景
+func secureRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // 合并自定义的字符串类型
 func joinCustomString[T fmt.Stringer](items []T, separator string) string {
 	switch len(items) {
@@ -0,0 +1,351 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TranscodingStatusFinished = "finished" // 转码完成状态
+	TranscodingStatusFailed   = "failed"   // 转码失败状态
+)
+
+// TranscodingPollInterval 等待转码完成时的轮询间隔
+var TranscodingPollInterval = time.Second * 5
+
+// templateBandwidths 各转码模板的估算码率(bit/s)
+var templateBandwidths = map[string]int{
+	"LD":  500000,
+	"SD":  1000000,
+	"HD":  2000000,
+	"FHD": 4000000,
+	"QHD": 8000000,
+}
+
+// VideoMediaMetadata 视频文件元数据
+type VideoMediaMetadata struct {
+	Duration              time.Duration           `json:"duration"`
+	Width                 int                     `json:"width"`
+	Height                int                     `json:"height"`
+	VideoMediaAudioStream []VideoMediaAudioStream `json:"video_media_audio_stream,omitempty"`
+	VideoMediaVideoStream []VideoMediaVideoStream `json:"video_media_video_stream,omitempty"`
+}
+
+// VideoMediaAudioStream 音频流信息
+type VideoMediaAudioStream struct {
+	BitRate       int64         `json:"bit_rate"` // bit/s
+	ChannelLayout string        `json:"channel_layout"`
+	Channels      int           `json:"channels"`
+	CodeName      string        `json:"code_name"`
+	Duration      time.Duration `json:"duration"`
+	SampleRate    int           `json:"sample_rate"` // Hz
+}
+
+// VideoMediaVideoStream 视频流信息
+type VideoMediaVideoStream struct {
+	Bitrate  int64         `json:"bitrate"` // bit/s
+	Clarity  string        `json:"clarity"`
+	CodeName string        `json:"code_name"`
+	Duration time.Duration `json:"duration"`
+	Fps      float64       `json:"fps"`
+}
+
+// VideoPreviewPlayInfo 视频转码播放信息
+type VideoPreviewPlayInfo struct {
+	Category                string                `json:"category"`
+	Meta                    VideoPreviewMeta      `json:"meta"`
+	LiveTranscodingTaskList []LiveTranscodingTask `json:"live_transcoding_task_list"`
+	// 字幕列表, 获取播放信息时 GetSubtitleInfo 为 true 才会返回
+	LiveTranscodingSubtitleTaskList []LiveTranscodingSubtitleTask `json:"live_transcoding_subtitle_task_list,omitempty"`
+}
+
+// VideoPreviewMeta 视频转码播放元数据
+type VideoPreviewMeta struct {
+	Duration time.Duration `json:"duration"`
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+}
+
+// LiveTranscodingTask 视频转码清晰度信息
+type LiveTranscodingTask struct {
+	TemplateId     string `json:"template_id"`
+	TemplateName   string `json:"template_name"`
+	TemplateWidth  int    `json:"template_width"`
+	TemplateHeight int    `json:"template_height"`
+	Status         string `json:"status"`
+	Stage          string `json:"stage"`
+	Url            string `json:"url"`
+}
+
+// LiveTranscodingSubtitleTask 视频字幕信息
+type LiveTranscodingSubtitleTask struct {
+	Language string `json:"language"`
+	Status   string `json:"status"`
+	Url      string `json:"url"`
+}
+
+// Finished 是否转码完成且有播放地址
+func (t *LiveTranscodingTask) Finished() bool {
+	return t.Status == TranscodingStatusFinished && t.Url != ""
+}
+
+// Bandwidth 估算码率(bit/s)
+func (t *LiveTranscodingTask) Bandwidth() int {
+	if bandwidth, ok := templateBandwidths[t.TemplateId]; ok {
+		return bandwidth
+	}
+	return t.TemplateWidth * t.TemplateHeight * 2
+}
+
+// Template 按 templateID 查找转码清晰度
+func (info *FileVideoPlayInfo) Template(templateID string) (template LiveTranscodingTask, ok bool) {
+	for _, t := range info.VideoPreviewPlayInfo.LiveTranscodingTaskList {
+		if t.TemplateId == templateID {
+			return t, true
+		}
+	}
+	return template, false
+}
+
+// BestTemplate 选择转码完成且高度不超过 maxHeight 的最高清晰度, maxHeight 小于等于 0 时不限制.
+// 都超过时返回最低清晰度
+func (info *FileVideoPlayInfo) BestTemplate(maxHeight int) (template LiveTranscodingTask, ok bool) {
+	return info.bestTemplate(func(t *LiveTranscodingTask) int {
+		return t.TemplateHeight
+	}, maxHeight)
+}
+
+// TemplateForBandwidth 选择转码完成且估算码率不超过 maxBandwidth(bit/s) 的最高清晰度.
+// 都超过时返回最低清晰度
+func (info *FileVideoPlayInfo) TemplateForBandwidth(maxBandwidth int) (template LiveTranscodingTask, ok bool) {
+	return info.bestTemplate(func(t *LiveTranscodingTask) int {
+		return t.Bandwidth()
+	}, maxBandwidth)
+}
+
+func (info *FileVideoPlayInfo) bestTemplate(value func(t *LiveTranscodingTask) int, limit int) (template LiveTranscodingTask, ok bool) {
+	var best, lowest *LiveTranscodingTask
+	tasks := info.VideoPreviewPlayInfo.LiveTranscodingTaskList
+	for i := range tasks {
+		t := &tasks[i]
+		if !t.Finished() {
+			continue
+		}
+
+		if lowest == nil || value(t) < value(lowest) {
+			lowest = t
+		}
+
+		if (limit <= 0 || value(t) <= limit) && (best == nil || value(t) > value(best)) {
+			best = t
+		}
+	}
+
+	if best != nil {
+		return *best, true
+	}
+	if lowest != nil {
+		return *lowest, true
+	}
+	return template, false
+}
+
+// WaitTranscoding 轮询等待指定清晰度转码完成, 返回包含播放地址的转码信息
+func (a *Authorize) WaitTranscoding(ctx context.Context, fileID, templateID string) (result LiveTranscodingTask, err error) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+
+		info, err := a.FileVideoPlayInfo(NewFileVideoPlayInfoOption(fileID))
+		if err != nil {
+			return result, err
+		}
+
+		template, ok := info.Template(templateID)
+		if !ok {
+			return result, fmt.Errorf("转码清晰度(%s)不存在", templateID)
+		}
+
+		if template.Finished() {
+			return template, nil
+		}
+
+		if template.Status == TranscodingStatusFailed {
+			return template, fmt.Errorf("转码清晰度(%s)转码失败", templateID)
+		}
+
+		timer.Reset(TranscodingPollInterval)
+	}
+}
+
+// jsonNumber 兼容字符串和数字两种格式的数值
+type jsonNumber string
+
+func (n *jsonNumber) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*n = jsonNumber(s)
+		return nil
+	}
+
+	if string(data) == "null" {
+		*n = ""
+		return nil
+	}
+
+	*n = jsonNumber(data)
+	return nil
+}
+
+func (n jsonNumber) float() float64 {
+	s := strings.TrimSpace(string(n))
+	// 帧率可能是分数形式, 例如 30000/1001
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		a, _ := strconv.ParseFloat(num, 64)
+		b, _ := strconv.ParseFloat(den, 64)
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	}
+
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func (n jsonNumber) int() int64 {
+	return int64(math.Round(n.float()))
+}
+
+// seconds 以秒为单位的数值转为 time.Duration
+func (n jsonNumber) seconds() time.Duration {
+	return time.Duration(n.float() * float64(time.Second))
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+func (m *VideoMediaMetadata) UnmarshalJSON(data []byte) error {
+	type alias VideoMediaMetadata
+	raw := struct {
+		*alias
+		Duration jsonNumber `json:"duration"`
+	}{alias: (*alias)(m)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Duration = raw.Duration.seconds()
+	return nil
+}
+
+func (m VideoMediaMetadata) MarshalJSON() ([]byte, error) {
+	type alias VideoMediaMetadata
+	return json.Marshal(struct {
+		alias
+		Duration string `json:"duration"`
+	}{alias: alias(m), Duration: formatSeconds(m.Duration)})
+}
+
+func (s *VideoMediaAudioStream) UnmarshalJSON(data []byte) error {
+	type alias VideoMediaAudioStream
+	raw := struct {
+		*alias
+		BitRate    jsonNumber `json:"bit_rate"`
+		Duration   jsonNumber `json:"duration"`
+		SampleRate jsonNumber `json:"sample_rate"`
+	}{alias: (*alias)(s)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	s.BitRate = raw.BitRate.int()
+	s.Duration = raw.Duration.seconds()
+	s.SampleRate = int(raw.SampleRate.int())
+	return nil
+}
+
+func (s VideoMediaAudioStream) MarshalJSON() ([]byte, error) {
+	type alias VideoMediaAudioStream
+	return json.Marshal(struct {
+		alias
+		BitRate    string `json:"bit_rate"`
+		Duration   string `json:"duration"`
+		SampleRate string `json:"sample_rate"`
+	}{
+		alias:      alias(s),
+		BitRate:    strconv.FormatInt(s.BitRate, 10),
+		Duration:   formatSeconds(s.Duration),
+		SampleRate: strconv.Itoa(s.SampleRate),
+	})
+}
+
+func (s *VideoMediaVideoStream) UnmarshalJSON(data []byte) error {
+	type alias VideoMediaVideoStream
+	raw := struct {
+		*alias
+		Bitrate  jsonNumber `json:"bitrate"`
+		Duration jsonNumber `json:"duration"`
+		Fps      jsonNumber `json:"fps"`
+	}{alias: (*alias)(s)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	s.Bitrate = raw.Bitrate.int()
+	s.Duration = raw.Duration.seconds()
+	s.Fps = raw.Fps.float()
+	return nil
+}
+
+func (s VideoMediaVideoStream) MarshalJSON() ([]byte, error) {
+	type alias VideoMediaVideoStream
+	return json.Marshal(struct {
+		alias
+		Bitrate  string `json:"bitrate"`
+		Duration string `json:"duration"`
+		Fps      string `json:"fps"`
+	}{
+		alias:    alias(s),
+		Bitrate:  strconv.FormatInt(s.Bitrate, 10),
+		Duration: formatSeconds(s.Duration),
+		Fps:      strconv.FormatFloat(s.Fps, 'f', -1, 64),
+	})
+}
+
+func (m *VideoPreviewMeta) UnmarshalJSON(data []byte) error {
+	type alias VideoPreviewMeta
+	raw := struct {
+		*alias
+		Duration jsonNumber `json:"duration"`
+	}{alias: (*alias)(m)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Duration = raw.Duration.seconds()
+	return nil
+}
+
+func (m VideoPreviewMeta) MarshalJSON() ([]byte, error) {
+	type alias VideoPreviewMeta
+	return json.Marshal(struct {
+		alias
+		Duration float64 `json:"duration"`
+	}{alias: alias(m), Duration: m.Duration.Seconds()})
+}
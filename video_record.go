@@ -0,0 +1,171 @@
+package aliyundrive_open
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// VideoRecord 视频播放记录
+type VideoRecord struct {
+	FileInfo
+	PlayCursor time.Duration `json:"play_cursor"` // 播放进度
+}
+
+// VideoRecentList 最近播放列表
+type VideoRecentList struct {
+	Items []VideoRecord `json:"items"`
+	ErrorInfo
+}
+
+// VideoPlayCursor 视频播放进度
+type VideoPlayCursor struct {
+	DriveId    string        `json:"drive_id"`
+	FileId     string        `json:"file_id"`
+	PlayCursor time.Duration `json:"play_cursor"` // 播放进度, 未播放过时为 0
+	Duration   time.Duration `json:"duration"`    // 视频时长
+}
+
+// Progress 播放进度百分比(0-1), 时长未知时返回 0
+func (c *VideoPlayCursor) Progress() float64 {
+	if c.Duration <= 0 {
+		return 0
+	}
+
+	progress := float64(c.PlayCursor) / float64(c.Duration)
+	if progress > 1 {
+		return 1
+	}
+	return progress
+}
+
+// VideoUpdatePlayCursor 更新视频播放进度
+func (a *Authorize) VideoUpdatePlayCursor(option *FileOption) (result VideoRecord, err error) {
+	if option == nil {
+		return result, fmt.Errorf("option is nil")
+	}
+
+	if err = a.requireScopes(ScopeWrite); err != nil {
+		return result, err
+	}
+
+	option.SetDriveID(a.DriveID)
+
+	err = a.HttpPost(APIVideoUpdateRecord, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("更新视频播放进度失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// VideoPlayCursor 获取视频播放进度
+func (a *Authorize) VideoPlayCursor(fileID string) (result VideoPlayCursor, err error) {
+	option := NewFileVideoPlayInfoOption(fileID)
+	option.WithPlayCursor = true
+	option.SetDriveID(a.DriveID)
+
+	var res struct {
+		FileVideoPlayInfo
+		PlayCursor jsonNumber `json:"play_cursor"`
+	}
+	err = a.HttpPost(APIFileVideoPlayInfo, option, &res)
+	if err != nil {
+		return result, err
+	}
+
+	if res.Code != "" {
+		return result, fmt.Errorf("获取视频播放进度失败: %s", res.Message)
+	}
+
+	result = VideoPlayCursor{
+		DriveId:    res.DriveId,
+		FileId:     res.FileId,
+		PlayCursor: res.PlayCursor.seconds(),
+		Duration:   res.VideoPreviewPlayInfo.Meta.Duration,
+	}
+	return result, nil
+}
+
+// VideoRecentList 获取最近播放列表
+func (a *Authorize) VideoRecentList(option *FileOption) (result VideoRecentList, err error) {
+	if option == nil {
+		option = NewVideoRecentListOption()
+	}
+	option.SetDriveID(a.DriveID)
+
+	err = a.HttpPost(APIVideoRecentList, option, &result)
+	if err != nil {
+		return result, err
+	}
+
+	if result.Code != "" {
+		err = fmt.Errorf("获取最近播放列表失败: %s", result.Message)
+	}
+
+	return result, err
+}
+
+// VideoSubtitles 获取视频字幕列表
+func (a *Authorize) VideoSubtitles(fileID string) (result []LiveTranscodingSubtitleTask, err error) {
+	option := NewFileVideoPlayInfoOption(fileID)
+	option.GetSubtitleInfo = true
+
+	info, err := a.FileVideoPlayInfo(option)
+	if err != nil {
+		return result, err
+	}
+
+	return info.VideoPreviewPlayInfo.LiveTranscodingSubtitleTaskList, nil
+}
+
+func (r *VideoRecord) UnmarshalJSON(data []byte) error {
+	type alias VideoRecord
+	raw := struct {
+		*alias
+		PlayCursor jsonNumber `json:"play_cursor"`
+	}{alias: (*alias)(r)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.PlayCursor = raw.PlayCursor.seconds()
+	return nil
+}
+
+func (r VideoRecord) MarshalJSON() ([]byte, error) {
+	type alias VideoRecord
+	return json.Marshal(struct {
+		alias
+		PlayCursor string `json:"play_cursor"`
+	}{alias: alias(r), PlayCursor: formatSeconds(r.PlayCursor)})
+}
+
+func (c *VideoPlayCursor) UnmarshalJSON(data []byte) error {
+	type alias VideoPlayCursor
+	raw := struct {
+		*alias
+		PlayCursor jsonNumber `json:"play_cursor"`
+		Duration   jsonNumber `json:"duration"`
+	}{alias: (*alias)(c)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	c.PlayCursor = raw.PlayCursor.seconds()
+	c.Duration = raw.Duration.seconds()
+	return nil
+}
+
+func (c VideoPlayCursor) MarshalJSON() ([]byte, error) {
+	type alias VideoPlayCursor
+	return json.Marshal(struct {
+		alias
+		PlayCursor string `json:"play_cursor"`
+		Duration   string `json:"duration"`
+	}{alias: alias(c), PlayCursor: formatSeconds(c.PlayCursor), Duration: formatSeconds(c.Duration)})
+}
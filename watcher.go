@@ -0,0 +1,213 @@
+package aliyundrive_open
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// WatchEventType 目录变化事件类型
+type WatchEventType string
+
+const (
+	WatchEventCreated  WatchEventType = "created"  // 新增
+	WatchEventModified WatchEventType = "modified" // 内容修改
+	WatchEventMoved    WatchEventType = "moved"    // 移动/重命名
+	WatchEventDeleted  WatchEventType = "deleted"  // 删除(包括放入回收站)
+)
+
+// SnapshotEntry 快照中的单个文件
+type SnapshotEntry struct {
+	FileID       string    `json:"file_id"`
+	Name         string    `json:"name"`
+	ParentFileID string    `json:"parent_file_id"`
+	Path         string    `json:"path"` // 相对监听目录的路径
+	Type         FileType  `json:"type"`
+	Size         int64     `json:"size,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ContentHash  string    `json:"content_hash,omitempty"`
+}
+
+// Snapshot 目录树快照, 以文件ID为键
+type Snapshot map[string]SnapshotEntry
+
+// WatchEvent 目录变化事件
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+	File SnapshotEntry  `json:"file"`          // 变化后的文件信息(删除时为删除前)
+	Old  *SnapshotEntry `json:"old,omitempty"` // 变化前的文件信息(修改/移动)
+}
+
+// Snapshot 获取目录树快照
+func (a *Authorize) Snapshot(folderID string) (snapshot Snapshot, err error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	snapshot = make(Snapshot)
+	err = a.snapshotFolder(folderID, "", snapshot)
+	return snapshot, err
+}
+
+func (a *Authorize) snapshotFolder(folderID, prefix string, snapshot Snapshot) error {
+	option := NewFileListOption(folderID, "")
+	for {
+		list, err := a.fileList(option, true)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range list.Items {
+			rel := path.Join(prefix, f.Name)
+			snapshot[f.FileId] = SnapshotEntry{
+				FileID:       f.FileId,
+				Name:         f.Name,
+				ParentFileID: f.ParentFileId,
+				Path:         rel,
+				Type:         f.Type,
+				Size:         f.Size,
+				UpdatedAt:    f.UpdatedAt,
+				ContentHash:  f.ContentHash,
+			}
+
+			if f.IsDir() {
+				if err = a.snapshotFolder(f.FileId, rel, snapshot); err != nil {
+					return err
+				}
+			}
+		}
+
+		if list.NextMarker == "" {
+			return nil
+		}
+		option.SetMarker(list.NextMarker)
+	}
+}
+
+// Diff 对比两个快照, 返回从 old 到 s 的变化事件, 按路径排序
+func (s Snapshot) Diff(old Snapshot) (events []WatchEvent) {
+	for id, cur := range s {
+		prev, ok := old[id]
+		if !ok {
+			events = append(events, WatchEvent{Type: WatchEventCreated, File: cur})
+			continue
+		}
+
+		if prev.Name != cur.Name || prev.ParentFileID != cur.ParentFileID {
+			p := prev
+			events = append(events, WatchEvent{Type: WatchEventMoved, File: cur, Old: &p})
+		}
+
+		if cur.Type != FileTypeFolder && (prev.ContentHash != cur.ContentHash || prev.Size != cur.Size || !prev.UpdatedAt.Equal(cur.UpdatedAt)) {
+			p := prev
+			events = append(events, WatchEvent{Type: WatchEventModified, File: cur, Old: &p})
+		}
+	}
+
+	for id, prev := range old {
+		if _, ok := s[id]; !ok {
+			events = append(events, WatchEvent{Type: WatchEventDeleted, File: prev})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].File.Path < events[j].File.Path
+	})
+	return events
+}
+
+// Watcher 通过定时快照对比监听云盘目录变化(开放平台没有推送通知)
+type Watcher struct {
+	FolderID   string           // 监听的目录ID
+	Interval   time.Duration    // 轮询间隔
+	Events     chan WatchEvent  // 变化事件
+	Errors     chan error       // 快照失败的错误, 不会中断监听
+	OnSnapshot func(s Snapshot) // 每次快照完成回调, 可用于持久化
+
+	GetAuthorize func() *Authorize // 获取当前授权信息(token 可能会被刷新)
+
+	snapshot Snapshot
+}
+
+// DefaultWatchInterval 默认轮询间隔
+var DefaultWatchInterval = time.Minute
+
+// NewWatcher 创建目录监听, 每次轮询通过 getAuthorize 获取授权信息, 可配合 AccountManager 长期运行
+func NewWatcher(getAuthorize func() *Authorize, folderID string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	return &Watcher{
+		FolderID: folderID,
+		Interval: interval,
+		Events:   make(chan WatchEvent, 100),
+		Errors:   make(chan error, 1),
+
+		GetAuthorize: getAuthorize,
+	}
+}
+
+// SetSnapshot 设置初始快照(例如从上次持久化的快照恢复), 未设置时首次快照只作为基准不产生事件
+func (w *Watcher) SetSnapshot(snapshot Snapshot) *Watcher {
+	w.snapshot = snapshot
+	return w
+}
+
+// Run 开始监听直到 ctx 结束, 结束后关闭 Events 和 Errors
+func (w *Watcher) Run(ctx context.Context) {
+	defer close(w.Events)
+	defer close(w.Errors)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context) {
+	a := w.GetAuthorize()
+	if a == nil {
+		w.reportError(fmt.Errorf("授权信息不可用"))
+		return
+	}
+
+	snapshot, err := a.Snapshot(w.FolderID)
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+
+	if w.snapshot != nil {
+		for _, event := range snapshot.Diff(w.snapshot) {
+			select {
+			case w.Events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	w.snapshot = snapshot
+	if w.OnSnapshot != nil {
+		w.OnSnapshot(snapshot)
+	}
+}
+
+// reportError 发送错误, Errors 已满时丢弃
+func (w *Watcher) reportError(err error) {
+	select {
+	case w.Errors <- err:
+	default:
+	}
+}